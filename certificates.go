@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"crypto/tls"
+	"github.com/sirupsen/logrus"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCertificateCheckInterval is a default interval of checking the certificate files for changes.
+const DefaultCertificateCheckInterval = 10 * time.Second
+
+// CertificateReloader holds the certificate loaded from the files and reloads it once the files change, so that
+// rotated certificates are picked up without restart. Files are checked lazily, during TLS handshakes.
+type CertificateReloader struct {
+	// CheckInterval is a minimal interval between checks of the files. DefaultCertificateCheckInterval is used if zero.
+	CheckInterval time.Duration
+	certFile      string
+	keyFile       string
+	lock          sync.Mutex
+	certificate   *tls.Certificate
+	fileStates    [2]fileState
+	lastCheck     time.Time
+}
+
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+// NewCertificateReloader function creates CertificateReloader, loading the certificate from the files.
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	certificateReloader := &CertificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := certificateReloader.Reload(); err != nil {
+		return nil, err
+	}
+	return certificateReloader, nil
+}
+
+// Reload method loads the certificate from the files unconditionally.
+func (cr *CertificateReloader) Reload() error {
+	fileStates, err := cr.statFiles()
+	if err != nil {
+		return err
+	}
+	certificate, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return err
+	}
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	cr.certificate, cr.fileStates, cr.lastCheck = &certificate, fileStates, time.Now()
+	return nil
+}
+
+// GetCertificate method returns the actual certificate, reloading it if the files have changed. If reloading fails
+// (e.g. the files are being rotated at the moment), the previous certificate is returned. Meant to be used as
+// tls.Config.GetCertificate.
+func (cr *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.lock.Lock()
+	certificate, fileStates := cr.certificate, cr.fileStates
+	checkRequired := time.Since(cr.lastCheck) >= durationOrDefault(cr.CheckInterval, DefaultCertificateCheckInterval)
+	if checkRequired {
+		cr.lastCheck = time.Now()
+	}
+	cr.lock.Unlock()
+	if !checkRequired {
+		return certificate, nil
+	}
+	if actualFileStates, err := cr.statFiles(); err != nil || actualFileStates == fileStates {
+		return certificate, nil
+	}
+	if err := cr.Reload(); err != nil {
+		logrus.WithError(err).Error("Certificate reloading failed, keeping the previous one")
+		return certificate, nil
+	}
+	logrus.WithField("certFile", cr.certFile).Info("Certificate reloaded")
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	return cr.certificate, nil
+}
+
+func (cr *CertificateReloader) statFiles() ([2]fileState, error) {
+	var fileStates [2]fileState
+	for i, file := range []string{cr.certFile, cr.keyFile} {
+		fileInfo, err := os.Stat(file)
+		if err != nil {
+			return fileStates, err
+		}
+		fileStates[i] = fileState{modTime: fileInfo.ModTime().UnixNano(), size: fileInfo.Size()}
+	}
+	return fileStates, nil
+}
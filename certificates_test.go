@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// generateCertificate creates a self-signed certificate, returning it in PEM.
+func generateCertificate(commonName string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	serialNumber, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		panic(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{commonName},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+func writeCertificate(certFile, keyFile, commonName string, modTime time.Time) {
+	certPEM, keyPEM := generateCertificate(commonName)
+	for file, content := range map[string][]byte{certFile: certPEM, keyFile: keyPEM} {
+		if err := os.WriteFile(file, content, 0600); err != nil {
+			panic(err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (suite *TestSuite) TestCertificateReloader() {
+	dir := suite.T().TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(certFile, keyFile, "first", time.Now().Add(-time.Minute))
+	certificateReloader, err := NewCertificateReloader(certFile, keyFile)
+	assert.NoError(suite.T(), err)
+	certificateReloader.CheckInterval = time.Nanosecond
+	certificate, err := certificateReloader.GetCertificate(nil)
+	assert.NoError(suite.T(), err)
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "first", leaf.Subject.CommonName)
+	assert.NoError(suite.T(), os.WriteFile(keyFile, []byte("broken"), 0600))
+	certificate, err = certificateReloader.GetCertificate(nil)
+	assert.NoError(suite.T(), err)
+	leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "first", leaf.Subject.CommonName)
+	writeCertificate(certFile, keyFile, "second", time.Now())
+	certificate, err = certificateReloader.GetCertificate(nil)
+	assert.NoError(suite.T(), err)
+	leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "second", leaf.Subject.CommonName)
+}
+
+func (suite *TestSuite) TestServerTLS() {
+	dir := suite.T().TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(certFile, keyFile, "first", time.Now().Add(-time.Minute))
+	addr := freeAddr()
+	testServer := NewServer(addr)
+	testServer.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS13}
+	testServer.CertificateCheckInterval = time.Nanosecond
+	errs := make(chan error, 1)
+	go func() {
+		errs <- testServer.ListenAndServeTLS(certFile, keyFile)
+	}()
+	client := http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}}
+	var response *http.Response
+	var err error
+	for i := 0; i < 100; i++ {
+		if response, err = client.Get("https://" + addr + "/endpoint1"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), uint16(tls.VersionTLS13), response.TLS.Version)
+	assert.Equal(suite.T(), "first", response.TLS.PeerCertificates[0].Subject.CommonName)
+	writeCertificate(certFile, keyFile, "second", time.Now())
+	response, err = client.Get("https://" + addr + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "second", response.TLS.PeerCertificates[0].Subject.CommonName)
+	assert.NoError(suite.T(), testServer.Shutdown(context.Background()))
+	assert.NoError(suite.T(), <-errs)
+}
+
+func (suite *TestSuite) TestServerTLSH2C() {
+	dir := suite.T().TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(certFile, keyFile, "h2", time.Now())
+	addr := freeAddr()
+	testServer := NewServer(addr)
+	testServer.H2C = true
+	errs := make(chan error, 1)
+	go func() {
+		errs <- testServer.ListenAndServeTLS(certFile, keyFile)
+	}()
+	client := http.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	var response *http.Response
+	var err error
+	for i := 0; i < 100; i++ {
+		if response, err = client.Get("https://" + addr + "/endpoint1"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), 2, response.ProtoMajor)
+		assert.Equal(suite.T(), "h2", response.TLS.NegotiatedProtocol)
+	}
+	assert.NoError(suite.T(), testServer.Shutdown(context.Background()))
+	assert.NoError(suite.T(), <-errs)
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client is a base of the clients generated by cmd/webgen: it sends the requests to the endpoints and decodes the
+// responses the same way the handlers encode them, with the same Serializer.
+type Client struct {
+	// BaseURL is a URL the paths of the endpoints are appended to, e.g. "https://api.example.org".
+	BaseURL string
+	// HTTPClient is used to send the requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Serializer encodes the request bodies and decodes the response bodies. Should match the GoiocSerializer bean of
+	// the server. Defaults to JsonSerializer.
+	Serializer Serializer
+	// ContentType is a Content-Type header of the bodies encoded by the Serializer. Defaults to "application/json".
+	ContentType string
+}
+
+// ClientRequest is a request to the endpoint, sent by Client.
+type ClientRequest struct {
+	// Method is an HTTP method of the request.
+	Method string
+	// Path is an escaped path of the request, appended to Client.BaseURL.
+	Path string
+	// Queries is a list of key-value pairs of the URL query part.
+	Queries []string
+	// Query is an additional URL query part.
+	Query url.Values
+	// Headers is a list of key-value pairs of the request headers.
+	Headers []string
+	// Header is an additional set of the request headers.
+	Header http.Header
+	// Body is a request body: string, []byte, io.Reader or an object encoded with encoding.BinaryMarshaler,
+	// encoding.TextMarshaler or the Serializer.
+	Body interface{}
+}
+
+// ClientError is an error returned by Client if the server responded with 4xx or 5xx status code.
+type ClientError struct {
+	// StatusCode is a status code of the response.
+	StatusCode int
+	// Header is a set of the response headers.
+	Header http.Header
+	// Body is a response body.
+	Body []byte
+}
+
+// Error method returns the status and the body of the response.
+func (ce *ClientError) Error() string {
+	message := "unexpected response status: " + strconv.Itoa(ce.StatusCode) + " " + http.StatusText(ce.StatusCode)
+	if body := strings.TrimSpace(string(ce.Body)); body != "" {
+		message += ": " + body
+	}
+	return message
+}
+
+// Do method sends the request and returns the response, or *ClientError if the response status code is 4xx or 5xx.
+// The caller is responsible for closing the response body, e.g. with Decode.
+func (c *Client) Do(ctx context.Context, request ClientRequest) (*http.Response, error) {
+	target := strings.TrimSuffix(c.BaseURL, "/") + request.Path
+	query := url.Values{}
+	for key, values := range request.Query {
+		query[key] = append(query[key], values...)
+	}
+	for i := 0; i+1 < len(request.Queries); i += 2 {
+		query.Add(request.Queries[i], request.Queries[i+1])
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	body, contentType, err := c.encode(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, request.Method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range request.Header {
+		for _, value := range values {
+			httpRequest.Header.Add(key, value)
+		}
+	}
+	for i := 0; i+1 < len(request.Headers); i += 2 {
+		httpRequest.Header.Set(request.Headers[i], request.Headers[i+1])
+	}
+	if contentType != "" && httpRequest.Header.Get("Content-Type") == "" {
+		httpRequest.Header.Set("Content-Type", contentType)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		defer response.Body.Close()
+		responseBody, err := io.ReadAll(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &ClientError{StatusCode: response.StatusCode, Header: response.Header, Body: responseBody}
+	}
+	return response, nil
+}
+
+// Decode method reads the response body to v (pointer to string, []byte, encoding.BinaryUnmarshaler,
+// encoding.TextUnmarshaler or an object decoded with the Serializer) and closes it. The body is discarded if v is nil.
+func (c *Client) Decode(response *http.Response, v interface{}) error {
+	defer response.Body.Close()
+	if v == nil {
+		_, err := io.Copy(io.Discard, response.Body)
+		return err
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	switch value := v.(type) {
+	case *string:
+		*value = string(body)
+	case *[]byte:
+		*value = body
+	case encoding.BinaryUnmarshaler:
+		return value.UnmarshalBinary(body)
+	case encoding.TextUnmarshaler:
+		return value.UnmarshalText(body)
+	default:
+		if len(body) == 0 {
+			return nil
+		}
+		return c.serializer().Deserialize(body, v)
+	}
+	return nil
+}
+
+func (c *Client) encode(body interface{}) (io.Reader, string, error) {
+	var data []byte
+	var err error
+	switch value := body.(type) {
+	case nil:
+		return nil, "", nil
+	case io.Reader:
+		return value, "", nil
+	case string:
+		return strings.NewReader(value), "text/plain; charset=utf-8", nil
+	case []byte:
+		return bytes.NewReader(value), "application/octet-stream", nil
+	case encoding.BinaryMarshaler:
+		data, err = value.MarshalBinary()
+		return bytes.NewReader(data), "application/octet-stream", err
+	case encoding.TextMarshaler:
+		data, err = value.MarshalText()
+		return bytes.NewReader(data), "text/plain; charset=utf-8", err
+	}
+	data, err = c.serializer().Serialize(body)
+	contentType := c.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return bytes.NewReader(data), contentType, err
+}
+
+func (c *Client) serializer() Serializer {
+	if c.Serializer == nil {
+		return JsonSerializer{}
+	}
+	return c.Serializer
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+)
+
+func (suite *TestSuite) TestClient() {
+	client := &Client{BaseURL: server.URL + "/"}
+	response, err := client.Do(context.Background(), ClientRequest{
+		Method: http.MethodPost,
+		Path:   "/json1",
+		Body:   jsonRequest{Name: "John"},
+	})
+	assert.NoError(suite.T(), err)
+	var result jsonResponse
+	assert.NoError(suite.T(), client.Decode(response, &result))
+	assert.Equal(suite.T(), jsonResponse{Greeting: "hello, John", Statements: 1}, result)
+	response, err = client.Do(context.Background(), ClientRequest{
+		Method:  http.MethodGet,
+		Path:    "/endpoint3",
+		Queries: []string{"id", "42"},
+		Query:   url.Values{"foo": {"bar"}},
+	})
+	assert.NoError(suite.T(), err)
+	var text string
+	assert.NoError(suite.T(), client.Decode(response, &text))
+	assert.Equal(suite.T(), "bar42", text)
+	response, err = client.Do(context.Background(), ClientRequest{Method: http.MethodPost, Path: "/function2", Body: "hello"})
+	assert.NoError(suite.T(), err)
+	var body []byte
+	assert.NoError(suite.T(), client.Decode(response, &body))
+	assert.Equal(suite.T(), "HELLO", string(body))
+}
+
+func (suite *TestSuite) TestClientError() {
+	client := &Client{BaseURL: server.URL}
+	_, err := client.Do(context.Background(), ClientRequest{Method: http.MethodGet, Path: "/function1/0"})
+	clientError, ok := err.(*ClientError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, clientError.StatusCode)
+	assert.EqualError(suite.T(), err, "unexpected response status: 500 Internal Server Error: Internal Server Error")
+}
@@ -0,0 +1,376 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// clientMethod accumulates the parameters, the request fields and the results of the generated client method.
+type clientMethod struct {
+	names      map[string]bool
+	parameters []string
+	request    []string
+	results    []string
+	values     []string
+	decode     string
+	stream     bool
+}
+
+// generateClient function parses the package in the directory and returns the formatted source of the client package
+// with a method per endpoint. The types declared in the parsed package are imported from it, so they must be exported.
+// The paths of the grouped endpoints are prefixed with the prefixes of their groups, which are configured at runtime
+// and hence must be given by the group ID.
+func generateClient(dir string, output string, clientPackage string, groups groupPrefixes) ([]byte, error) {
+	fset, files, err := parsePackage(dir, output)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := findEndpoints(files)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{fset: fset, imports: map[string]string{"context": "", webImportPath: ""}, packageName: files[0].Name.Name}
+	g.packagePath, err = packageImportPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&g.body, "// Client is a client of the endpoints of package %s.\n", g.packageName)
+	g.body.WriteString("type Client struct {\nweb.Client\n}\n")
+	for _, endpoint := range endpoints {
+		if err := g.generateClientMethod(endpoint, groups); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", endpoint.typeName, endpoint.handlerFunc.Name.Name, err)
+		}
+	}
+	return g.source(clientPackage, g.body.String())
+}
+
+// generateClientMethod method writes the client method sending the request to the endpoint.
+func (g *generator) generateClientMethod(endpoint endpoint, groups groupPrefixes) error {
+	funcType := endpoint.handlerFunc.Type
+	if funcType.TypeParams != nil {
+		return errors.New("generic handlers are not supported")
+	}
+	m := &clientMethod{names: map[string]bool{"c": true, "ctx": true, "response": true, "err": true}}
+	m.parameters = append(m.parameters, "ctx context.Context")
+	path := endpoint.tag("web.path")
+	if group := endpoint.tag("web.group"); group != "" {
+		prefix, ok := groups[group]
+		if !ok {
+			return fmt.Errorf("prefix of group %s is unknown, set it with -group %s=/prefix", group, group)
+		}
+		path = prefix + path
+	}
+	pathExpr, err := g.clientPath(m, path)
+	if err != nil {
+		return err
+	}
+	if queries := endpoint.tag("web.queries"); queries != "" {
+		m.request = append(m.request, "Queries: []string{"+g.clientPairs(m, strings.Split(queries, ","))+"}")
+	}
+	if headers := endpoint.tag("web.headers"); headers != "" {
+		m.request = append(m.request, "Headers: []string{"+g.clientPairs(m, strings.Split(headers, ","))+"}")
+	}
+	hasBody := false
+	for _, field := range fieldList(funcType.Params) {
+		switch key := g.typeKey(endpoint.file, field); key {
+		case "context.Context", "net/http.ResponseWriter", "*net/http.Request", "map[string]string",
+			"*crypto/x509.Certificate", "*" + webImportPath + ".ClientIdentity":
+		case "net/url.Values":
+			g.imports["net/url"] = ""
+			m.request = append(m.request, "Query: "+m.parameter("query", "url.Values"))
+		case "net/http.Header":
+			g.imports["net/http"] = ""
+			m.request = append(m.request, "Header: "+m.parameter("header", "http.Header"))
+		default:
+			if hasBody {
+				continue
+			}
+			typeString := "io.Reader"
+			if key != "io.Reader" && key != "io.ReadCloser" {
+				if typeString, err = g.clientType(endpoint.file, field); err != nil {
+					return err
+				}
+			} else {
+				g.imports["io"] = ""
+			}
+			m.request = append(m.request, "Body: "+m.parameter("body", typeString))
+			hasBody = true
+		}
+	}
+	if err := g.clientResults(m, endpoint.file, fieldList(funcType.Results)); err != nil {
+		return err
+	}
+	method := "GET"
+	if methods := endpoint.tag("web.methods"); methods != "" {
+		method = strings.TrimSpace(strings.Split(methods, ",")[0])
+	} else if hasBody {
+		method = "POST"
+	}
+	name := exportedName(endpoint.typeName)
+	fmt.Fprintf(&g.body, "\n// %s method sends %s %s request.\n", name, method, path)
+	fmt.Fprintf(&g.body, "func (c *Client) %s(%s) (%s) {\n", name, strings.Join(m.parameters, ", "),
+		strings.Join(append(m.results, "err error"), ", "))
+	g.body.WriteString("response, err := c.Do(ctx, web.ClientRequest{\n")
+	fmt.Fprintf(&g.body, "Method: %q,\nPath: %s,\n", method, pathExpr)
+	for _, field := range m.request {
+		g.body.WriteString(field + ",\n")
+	}
+	g.body.WriteString("})\nif err != nil {\nreturn\n}\n")
+	switch {
+	case m.stream:
+		m.values = append(m.values, "nil")
+	case m.decode != "":
+		g.body.WriteString("err = c.Decode(response, &" + m.decode + ")\n")
+		m.values = append(m.values, "err")
+	default:
+		m.values = append(m.values, "c.Decode(response, nil)")
+	}
+	g.body.WriteString("return " + strings.Join(m.values, ", ") + "\n}\n")
+	return nil
+}
+
+// clientPath method returns the expression building the path of the request, adding a parameter per path variable.
+func (g *generator) clientPath(m *clientMethod, path string) (string, error) {
+	var parts []string
+	for rest := path; rest != ""; {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			parts = append(parts, strconv.Quote(rest))
+			break
+		}
+		end := variableEnd(rest, start)
+		if end < 0 {
+			return "", errors.New("unbalanced braces in path: " + path)
+		}
+		if start > 0 {
+			parts = append(parts, strconv.Quote(rest[:start]))
+		}
+		g.imports["net/url"] = ""
+		parts = append(parts, "url.PathEscape("+m.parameter(variableName(rest[start+1:end]), "string")+")")
+		rest = rest[end+1:]
+	}
+	if len(parts) == 0 {
+		return `""`, nil
+	}
+	return strings.Join(parts, " + "), nil
+}
+
+// clientPairs method returns the list of key-value pairs, adding a parameter per variable value, e.g. "{id}".
+func (g *generator) clientPairs(m *clientMethod, pairs []string) string {
+	values := make([]string, 0, len(pairs))
+	for i, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if i%2 == 1 && strings.HasPrefix(pair, "{") && variableEnd(pair, 0) == len(pair)-1 {
+			values = append(values, m.parameter(variableName(pair[1:len(pair)-1]), "string"))
+			continue
+		}
+		values = append(values, strconv.Quote(pair))
+	}
+	return strings.Join(values, ", ")
+}
+
+// clientResults method adds the results of the client method, corresponding to the results of the handler method.
+func (g *generator) clientResults(m *clientMethod, file *ast.File, fields []ast.Expr) error {
+	if len(fields) > 0 && g.typeKey(file, fields[len(fields)-1]) == "error" {
+		fields = fields[:len(fields)-1]
+	}
+	for _, field := range fields {
+		switch key := g.typeKey(file, field); key {
+		case "net/http.Header":
+			m.result("header", "http.Header", "response.Header")
+			continue
+		case "int":
+			m.result("statusCode", "int", "response.StatusCode")
+			continue
+		case "io.Reader", "io.ReadCloser":
+			g.imports["io"] = ""
+			m.result("result", "io.ReadCloser", "response.Body")
+			m.stream = true
+		case "html/template.Template", "*html/template.Template", "text/template.Template", "*text/template.Template",
+			webImportPath + ".View":
+			m.decode = m.result("result", "string", "")
+		default:
+			typeString, err := g.clientType(file, field)
+			if err != nil {
+				return err
+			}
+			m.decode = m.result("result", typeString, "")
+		}
+		// the rest of the results are not written by the handler
+		return nil
+	}
+	return nil
+}
+
+// clientType method returns the type expression usable from the client package, qualifying the types declared in
+// the parsed package with its name.
+func (g *generator) clientType(file *ast.File, expr ast.Expr) (string, error) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(expr.Name) != nil {
+			return expr.Name, nil
+		}
+		if !ast.IsExported(expr.Name) {
+			return "", errors.New("unexported types are not supported by the client: " + expr.Name)
+		}
+		name := ""
+		if g.packageName != filepath.Base(g.packagePath) {
+			name = g.packageName
+		}
+		g.imports[g.packagePath] = name
+		return g.packageName + "." + expr.Name, nil
+	case *ast.StarExpr:
+		elem, err := g.clientType(file, expr.X)
+		return "*" + elem, err
+	case *ast.ArrayType:
+		elem, err := g.clientType(file, expr.Elt)
+		if expr.Len != nil {
+			return "[" + g.exprString(expr.Len) + "]" + elem, err
+		}
+		return "[]" + elem, err
+	case *ast.MapType:
+		key, err := g.clientType(file, expr.Key)
+		if err != nil {
+			return "", err
+		}
+		value, err := g.clientType(file, expr.Value)
+		return "map[" + key + "]" + value, err
+	}
+	return g.typeString(file, expr), nil
+}
+
+// parameter method adds the parameter with the unique name and returns the name.
+func (m *clientMethod) parameter(name string, typeString string) string {
+	name = m.unique(name)
+	m.parameters = append(m.parameters, name+" "+typeString)
+	return name
+}
+
+// result method adds the named result, returned as the value (or the result itself, if the value is empty), and returns
+// the name.
+func (m *clientMethod) result(name string, typeString string, value string) string {
+	name = m.unique(name)
+	m.results = append(m.results, name+" "+typeString)
+	if value == "" {
+		value = name
+	}
+	m.values = append(m.values, value)
+	return name
+}
+
+func (m *clientMethod) unique(name string) string {
+	unique := name
+	for i := 2; m.names[unique] || token.IsKeyword(unique) || types.Universe.Lookup(unique) != nil; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	m.names[unique] = true
+	return unique
+}
+
+// variableEnd function returns the index of the brace closing the variable that starts at the index, taking into
+// account the braces of the pattern, e.g. "{id:[0-9]{4}}".
+func variableEnd(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// variableName function converts the name of the variable (without the pattern) to Go identifier, e.g. "user-id" to
+// "userId".
+func variableName(variable string) string {
+	variable, _, _ = strings.Cut(variable, ":")
+	var name strings.Builder
+	upper := false
+	for _, r := range strings.TrimSpace(variable) {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_':
+			upper = name.Len() > 0
+		case name.Len() == 0 && unicode.IsDigit(r):
+			name.WriteString("v")
+			name.WriteRune(r)
+		case upper:
+			name.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			name.WriteRune(r)
+		}
+	}
+	if name.Len() == 0 {
+		return "value"
+	}
+	// lower the leading initialism, e.g. "ID" to "id" and "URLPath" to "urlPath"
+	runes := []rune(name.String())
+	leading := 0
+	for leading < len(runes) && unicode.IsUpper(runes[leading]) {
+		leading++
+	}
+	if leading > 1 && leading < len(runes) {
+		leading--
+	}
+	for i := 0; i < leading; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+func exportedName(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// packageImportPath function resolves the import path of the package in the directory using the nearest go.mod file.
+func packageImportPath(dir string) (string, error) {
+	absolute, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for current := absolute; ; current = filepath.Dir(current) {
+		data, err := os.ReadFile(filepath.Join(current, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+					relative, err := filepath.Rel(current, absolute)
+					if err != nil || relative == "." {
+						return strings.Trim(fields[1], `"`), err
+					}
+					return strings.Trim(fields[1], `"`) + "/" + filepath.ToSlash(relative), nil
+				}
+			}
+			return "", errors.New("module directive is not found in " + filepath.Join(current, "go.mod"))
+		}
+		if filepath.Dir(current) == current {
+			return "", errors.New("go.mod is not found for " + dir)
+		}
+	}
+}
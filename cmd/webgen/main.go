@@ -0,0 +1,501 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Command webgen generates reflection-free handlers for the Endpoint types of the package. Add the directive below to
+// any file of the package and run `go generate`:
+//
+//	//go:generate go run github.com/goioc/web/cmd/webgen
+//
+// The generated file registers the handlers with web.RegisterHandlerFactory, so that the routers use them instead of
+// the reflection-based ones. Unsupported handler signatures are reported as errors.
+//
+// With -client flag, webgen generates a client package instead, with a method per endpoint:
+//
+//	//go:generate go run github.com/goioc/web/cmd/webgen -client usersclient -output ../usersclient/client_gen.go
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const webImportPath = "github.com/goioc/web"
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package to generate the handlers for")
+	output := flag.String("output", "web_handlers_gen.go", "name of the generated file, relative to the directory")
+	client := flag.String("client", "", "name of the client package to generate instead of the handlers")
+	groups := make(groupPrefixes)
+	flag.Var(groups, "group", "path prefix of the group for the client, e.g. api=/api/v1 (repeatable)")
+	flag.Parse()
+	var source []byte
+	var err error
+	if *client != "" {
+		source, err = generateClient(*dir, *output, *client, groups)
+	} else {
+		source, err = generate(*dir, *output)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "webgen:", err)
+		os.Exit(1)
+	}
+	file := filepath.Join(*dir, *output)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		fmt.Fprintln(os.Stderr, "webgen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(file, source, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "webgen:", err)
+		os.Exit(1)
+	}
+}
+
+// groupPrefixes maps the IDs of the groups to their path prefixes. It's a flag.Value accepting "ID=prefix".
+type groupPrefixes map[string]string
+
+func (g groupPrefixes) String() string {
+	pairs := make([]string, 0, len(g))
+	for group, prefix := range g {
+		pairs = append(pairs, group+"="+prefix)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (g groupPrefixes) Set(value string) error {
+	group, prefix, ok := strings.Cut(value, "=")
+	if !ok || group == "" {
+		return errors.New("group is expected as ID=prefix: " + value)
+	}
+	g[group] = prefix
+	return nil
+}
+
+// endpoint is an Endpoint type found in the package.
+type endpoint struct {
+	typeName    string
+	handlerFunc *ast.FuncDecl
+	file        *ast.File
+	tags        []reflect.StructTag
+}
+
+// generator accumulates the generated code and the imports it requires.
+type generator struct {
+	fset    *token.FileSet
+	imports map[string]string
+	body    bytes.Buffer
+	// packagePath and packageName refer to the parsed package from the generated client.
+	packagePath string
+	packageName string
+}
+
+// generate function parses the package in the directory (ignoring tests and the output file) and returns the formatted
+// source of the generated handlers.
+func generate(dir string, output string) ([]byte, error) {
+	fset, files, err := parsePackage(dir, output)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := findEndpoints(files)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{fset: fset, imports: map[string]string{"net/http": "", "reflect": "", webImportPath: ""}}
+	for _, endpoint := range endpoints {
+		if err := g.generateHandler(endpoint); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", endpoint.typeName, endpoint.handlerFunc.Name.Name, err)
+		}
+	}
+	return g.source(files[0].Name.Name, "func init() {\n"+g.body.String()+"}\n")
+}
+
+// parsePackage function parses the Go files of the package in the directory, except for tests and the output file.
+func parsePackage(dir string, output string) (*token.FileSet, []*ast.File, error) {
+	fset := token.NewFileSet()
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") || filepath.Base(name) == output {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, nil, errors.New("no Go files found in " + dir)
+	}
+	return fset, files, nil
+}
+
+// routeTags are the tags configuring the route of the endpoint. Types without any of them are not considered endpoints.
+var routeTags = []string{"web.methods", "web.path", "web.queries", "web.headers", "web.matcher"}
+
+// findEndpoints function returns the types having HandlerFuncName method that returns a string literal and at least one
+// of the route tags, sorted by name.
+func findEndpoints(files []*ast.File) ([]endpoint, error) {
+	methods := make(map[string]map[string]*ast.FuncDecl)
+	methodFiles := make(map[*ast.FuncDecl]*ast.File)
+	tags := make(map[string][]reflect.StructTag)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok {
+				for _, spec := range genDecl.Specs {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+						tags[typeSpec.Name.Name] = structTags(typeSpec.Type)
+					}
+				}
+				continue
+			}
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) != 1 {
+				continue
+			}
+			typeName := receiverTypeName(funcDecl.Recv.List[0].Type)
+			if typeName == "" {
+				continue
+			}
+			if methods[typeName] == nil {
+				methods[typeName] = make(map[string]*ast.FuncDecl)
+			}
+			methods[typeName][funcDecl.Name.Name] = funcDecl
+			methodFiles[funcDecl] = file
+		}
+	}
+	var endpoints []endpoint
+	for typeName, typeMethods := range methods {
+		handlerFuncNameDecl, ok := typeMethods["HandlerFuncName"]
+		if !ok || !hasRouteTag(tags[typeName]) {
+			continue
+		}
+		handlerFuncName, err := returnedString(handlerFuncNameDecl)
+		if err != nil {
+			return nil, fmt.Errorf("%s.HandlerFuncName: %w", typeName, err)
+		}
+		handlerFunc, ok := typeMethods[handlerFuncName]
+		if !ok {
+			return nil, fmt.Errorf("%s: handler method not found: %s", typeName, handlerFuncName)
+		}
+		endpoints = append(endpoints, endpoint{
+			typeName:    typeName,
+			handlerFunc: handlerFunc,
+			file:        methodFiles[handlerFunc],
+			tags:        tags[typeName],
+		})
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].typeName < endpoints[j].typeName
+	})
+	return endpoints, nil
+}
+
+// structTags function returns the tags of the fields of the structure type.
+func structTags(expr ast.Expr) []reflect.StructTag {
+	structType, ok := expr.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	var tags []reflect.StructTag
+	for _, field := range structType.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+			tags = append(tags, reflect.StructTag(tag))
+		}
+	}
+	return tags
+}
+
+func hasRouteTag(tags []reflect.StructTag) bool {
+	for _, tag := range tags {
+		for _, key := range routeTags {
+			if _, ok := tag.Lookup(key); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tag method returns the value of the first field tag with the key, e.g. "web.path".
+func (e endpoint) tag(key string) string {
+	for _, tag := range e.tags {
+		if value, ok := tag.Lookup(key); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func returnedString(funcDecl *ast.FuncDecl) (string, error) {
+	if funcDecl.Body != nil && len(funcDecl.Body.List) == 1 {
+		if returnStmt, ok := funcDecl.Body.List[0].(*ast.ReturnStmt); ok && len(returnStmt.Results) == 1 {
+			if basicLit, ok := returnStmt.Results[0].(*ast.BasicLit); ok && basicLit.Kind == token.STRING {
+				return strconv.Unquote(basicLit.Value)
+			}
+		}
+	}
+	return "", errors.New("must return a string literal")
+}
+
+// generateHandler method writes the registration of the handler factory for the endpoint.
+func (g *generator) generateHandler(endpoint endpoint) error {
+	funcType := endpoint.handlerFunc.Type
+	if funcType.TypeParams != nil {
+		return errors.New("generic handlers are not supported")
+	}
+	var arguments, statements []string
+	for i, field := range fieldList(funcType.Params) {
+		argument, ok := g.argument(endpoint.file, field)
+		if !ok {
+			argument = "arg" + strconv.Itoa(i)
+			statements = append(statements,
+				"var "+argument+" "+g.typeString(endpoint.file, field),
+				"web.DecodeBody(r, &"+argument+")")
+		}
+		arguments = append(arguments, argument)
+	}
+	call := "bean." + endpoint.handlerFunc.Name.Name + "(" + strings.Join(arguments, ", ") + ")"
+	resultStatements, err := g.results(endpoint.file, fieldList(funcType.Results), call)
+	if err != nil {
+		return err
+	}
+	statements = append(statements, resultStatements...)
+	fmt.Fprintf(&g.body, "web.RegisterHandlerFactory(reflect.TypeOf((*%s)(nil)), %q, "+
+		"func(resolveBean func(*http.Request) interface{}) http.Handler {\n", endpoint.typeName, endpoint.handlerFunc.Name.Name)
+	g.body.WriteString("return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {\n")
+	fmt.Fprintf(&g.body, "bean := resolveBean(r).(*%s)\n", endpoint.typeName)
+	for _, statement := range statements {
+		g.body.WriteString(statement + "\n")
+	}
+	g.body.WriteString("})\n})\n")
+	return nil
+}
+
+// argument method returns the expression of the argument resolved from the request, or false if the argument is the
+// request body.
+func (g *generator) argument(file *ast.File, field ast.Expr) (string, bool) {
+	switch g.typeKey(file, field) {
+	case "context.Context":
+		return "r.Context()", true
+	case "net/http.ResponseWriter":
+		return "w", true
+	case "*net/http.Request":
+		return "r", true
+	case "net/http.Header":
+		return "r.Header", true
+	case "io.Reader", "io.ReadCloser":
+		return "r.Body", true
+	case "[]byte":
+		return "web.ReadBody(r)", true
+	case "string":
+		return "string(web.ReadBody(r))", true
+	case "map[string]string":
+		g.imports["github.com/gorilla/mux"] = ""
+		return "mux.Vars(r)", true
+	case "net/url.Values":
+		return "r.URL.Query()", true
+	case "*crypto/x509.Certificate":
+		return "web.RequestClientCertificate(r)", true
+	case "*" + webImportPath + ".ClientIdentity":
+		return "web.RequestClientIdentity(r)", true
+	}
+	return "", false
+}
+
+// results method returns the statements calling the handler method and writing its results to the response.
+func (g *generator) results(file *ast.File, fields []ast.Expr, call string) ([]string, error) {
+	if len(fields) == 0 {
+		return []string{call}, nil
+	}
+	variables := make([]string, len(fields))
+	for i := range fields {
+		variables[i] = "res" + strconv.Itoa(i)
+	}
+	// unlike the function endpoints, the endpoints don't handle error results specially: such results are written as
+	// any other ones, the same way as the reflection-based handlers do
+	var statements []string
+	statusCode, written := false, false
+	writeHeader := func() {
+		if statusCode {
+			statements = append(statements, "if statusCode != 0 {", "w.WriteHeader(statusCode)", "}")
+		}
+	}
+	for i, field := range fields {
+		if written {
+			variables[i] = "_"
+			continue
+		}
+		variable := variables[i]
+		switch key := g.typeKey(file, field); key {
+		case "net/http.Header":
+			statements = append(statements, "for key, values := range "+variable+" {", "for _, value := range values {",
+				"w.Header().Add(key, value)", "}", "}")
+		case "int":
+			if statusCode {
+				statements = append(statements, "statusCode = "+variable)
+			} else {
+				statements = append(statements, "statusCode := "+variable)
+			}
+			statusCode = true
+		case "string", "[]byte":
+			writeHeader()
+			statements = append(statements, "if _, err := w.Write([]byte("+variable+")); err != nil {", "panic(err)", "}")
+			written = true
+		case "io.Reader":
+			writeHeader()
+			g.imports["io"] = ""
+			statements = append(statements, "if _, err := io.Copy(w, "+variable+"); err != nil {", "panic(err)", "}")
+			written = true
+		case "io.ReadCloser":
+			writeHeader()
+			g.imports["io"] = ""
+			statements = append(statements, "if _, err := io.Copy(w, "+variable+"); err != nil {", "panic(err)", "}",
+				"if err := "+variable+".Close(); err != nil {", "panic(err)", "}")
+			written = true
+		case "html/template.Template", "*html/template.Template", "text/template.Template", "*text/template.Template",
+			webImportPath + ".View":
+			return nil, errors.New("template results are not supported: " + key)
+		default:
+			if statusCode {
+				statements = append(statements, "web.WriteObject(w, statusCode, "+variable+")")
+			} else {
+				statements = append(statements, "web.WriteObject(w, 0, "+variable+")")
+			}
+			written = true
+		}
+	}
+	if !written {
+		writeHeader()
+	}
+	return append([]string{strings.Join(variables, ", ") + " := " + call}, statements...), nil
+}
+
+// typeKey method returns the type expression with package names replaced by the import paths, e.g. "*net/http.Request".
+func (g *generator) typeKey(file *ast.File, expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + g.typeKey(file, expr.X)
+	case *ast.SelectorExpr:
+		if ident, ok := expr.X.(*ast.Ident); ok {
+			if importPath, ok := importPath(file, ident.Name); ok {
+				return importPath + "." + expr.Sel.Name
+			}
+		}
+	}
+	return g.exprString(expr)
+}
+
+// typeString method returns the type expression as is, registering the imports it refers to.
+func (g *generator) typeString(file *ast.File, expr ast.Expr) string {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if selectorExpr, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selectorExpr.X.(*ast.Ident); ok {
+				if path, ok := importPath(file, ident.Name); ok {
+					name := ""
+					if ident.Name != filepath.Base(path) {
+						name = ident.Name
+					}
+					g.imports[path] = name
+				}
+			}
+		}
+		return true
+	})
+	return g.exprString(expr)
+}
+
+func (g *generator) exprString(expr ast.Expr) string {
+	var buffer bytes.Buffer
+	if err := printer.Fprint(&buffer, g.fset, expr); err != nil {
+		panic(err)
+	}
+	return buffer.String()
+}
+
+// source method returns the formatted source of the generated file with the declarations.
+func (g *generator) source(packageName string, declarations string) ([]byte, error) {
+	var source bytes.Buffer
+	source.WriteString("// Code generated by webgen. DO NOT EDIT.\n\n")
+	source.WriteString("package " + packageName + "\n\n")
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	source.WriteString("import (\n")
+	for _, path := range paths {
+		source.WriteString(g.imports[path] + " " + strconv.Quote(path) + "\n")
+	}
+	source.WriteString(")\n\n")
+	source.WriteString(declarations)
+	return format.Source(source.Bytes())
+}
+
+// importPath function resolves the package name used in the file to the import path.
+func importPath(file *ast.File, name string) (string, bool) {
+	for _, importSpec := range file.Imports {
+		path, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if importSpec.Name != nil && importSpec.Name.Name == name || importSpec.Name == nil && filepath.Base(path) == name {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// fieldList function flattens the parameters or results, repeating the type of the grouped names.
+func fieldList(fieldList *ast.FieldList) []ast.Expr {
+	if fieldList == nil {
+		return nil
+	}
+	var types []ast.Expr
+	for _, field := range fieldList.List {
+		for i := 0; i < len(field.Names) || i == 0; i++ {
+			types = append(types, field.Type)
+		}
+	}
+	return types
+}
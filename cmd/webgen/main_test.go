@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	source, err := generate("testdata/endpoints", "web_handlers_gen.go")
+	assert.NoError(t, err)
+	golden, err := os.ReadFile("testdata/endpoints/web_handlers_gen.go.golden")
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), string(source))
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	_, err := generate("testdata/unsupported", "web_handlers_gen.go")
+	assert.EqualError(t, err, "page.Render: template results are not supported: "+webImportPath+".View")
+}
+
+func TestGenerateClient(t *testing.T) {
+	source, err := generateClient("testdata/endpoints", "client_gen.go", "endpointsclient",
+		groupPrefixes{"admin": "/admin"})
+	assert.NoError(t, err)
+	golden, err := os.ReadFile("testdata/endpoints/client_gen.go.golden")
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), string(source))
+}
+
+func TestGenerateClientUnexported(t *testing.T) {
+	_, err := generateClient("testdata/unexported", "client_gen.go", "client", nil)
+	assert.EqualError(t, err, "getItem.Get: unexported types are not supported by the client: item")
+}
+
+func TestGenerateClientUnknownGroup(t *testing.T) {
+	_, err := generateClient("testdata/endpoints", "client_gen.go", "endpointsclient", nil)
+	assert.EqualError(t, err, "deleteUser.Delete: prefix of group admin is unknown, set it with -group admin=/prefix")
+}
+
+func TestGroupPrefixes(t *testing.T) {
+	groups := make(groupPrefixes)
+	assert.NoError(t, groups.Set("api=/api/v1"))
+	assert.NoError(t, groups.Set("admin=/admin"))
+	assert.Equal(t, "admin=/admin,api=/api/v1", groups.String())
+	assert.Error(t, groups.Set("/api"))
+}
+
+func TestVariableName(t *testing.T) {
+	for variable, name := range map[string]string{
+		"id":          "id",
+		"ID:[0-9]+":   "id",
+		"user-id":     "userId",
+		"2fa":         "v2fa",
+		"post_id:{3}": "post_id",
+		"URLPath":     "urlPath",
+		"-":           "value",
+		"Group.Name":  "groupName",
+	} {
+		assert.Equal(t, name, variableName(variable), variable)
+	}
+}
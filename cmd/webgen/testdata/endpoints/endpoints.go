@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type User struct {
+	Name    string
+	Created time.Time
+}
+
+type getUser struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/users/{id}"`
+}
+
+func (e getUser) HandlerFuncName() string {
+	return "Get"
+}
+
+func (e *getUser) Get(ctx context.Context, vars map[string]string) (http.Header, int, *User, error) {
+	if vars["id"] == "" {
+		return nil, 0, nil, errors.New("id is required")
+	}
+	return http.Header{"X-Id": {vars["id"]}}, http.StatusOK, &User{Name: vars["id"]}, nil
+}
+
+type createUser struct {
+	method interface{} `web.methods:"POST"`
+	path   interface{} `web.path:"/users"`
+}
+
+func (e createUser) HandlerFuncName() string {
+	return "Create"
+}
+
+func (e *createUser) Create(u User, header http.Header) int {
+	return http.StatusCreated
+}
+
+type echo struct {
+	path interface{} `web.path:"/echo"`
+}
+
+func (e echo) HandlerFuncName() string {
+	return "Echo"
+}
+
+func (e echo) Echo(body string, w http.ResponseWriter) io.Reader {
+	return strings.NewReader(body)
+}
+
+type search struct {
+	method  interface{} `web.methods:"GET"`
+	path    interface{} `web.path:"/users/{group}/search"`
+	queries interface{} `web.queries:"q,{query:[a-z]+}"`
+	headers interface{} `web.headers:"Accept,application/json"`
+}
+
+func (e search) HandlerFuncName() string {
+	return "Search"
+}
+
+func (e search) Search(vars map[string]string, query url.Values) ([]User, error) {
+	return []User{{Name: vars["group"] + ":" + query.Get("q")}}, nil
+}
+
+type deleteUser struct {
+	method interface{} `web.methods:"DELETE"`
+	path   interface{} `web.path:"/users/{id}"`
+	group  interface{} `web.group:"admin"`
+}
+
+func (e deleteUser) HandlerFuncName() string {
+	return "Delete"
+}
+
+func (e deleteUser) Delete(vars map[string]string) error {
+	return nil
+}
+
+// notEndpoint has HandlerFuncName method, but no route tags, so it's not an endpoint.
+type notEndpoint struct {
+	name string
+}
+
+func (n notEndpoint) HandlerFuncName() string {
+	return n.name
+}
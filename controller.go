@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"github.com/gorilla/mux"
+	"reflect"
+)
+
+// Controller is an interface representing a bean that exposes multiple web endpoints, one per method.
+type Controller interface {
+	// Routes should return specifications of the routes served by the bean's methods.
+	Routes() []RouteSpec
+}
+
+// RouteSpec is a specification of the route served by the Controller method. Its fields have the same meaning as the
+// corresponding `web.*` tags of the Endpoint.
+type RouteSpec struct {
+	// HandlerFuncName is a method name that is going to be used to create http handler.
+	HandlerFuncName string
+	// Methods is a list of HTTP-methods.
+	Methods []string
+	// Path is a URL sub-path. Can contain path variables.
+	Path string
+	// Queries is a list of key-value pairs of the URL query part.
+	Queries []string
+	// Headers is a list of key-value pairs of the request headers.
+	Headers []string
+	// Matcher is an ID of the bean of type *mux.MatcherFunc.
+	Matcher string
+	// Group is an ID of the Group bean the route belongs to.
+	Group string
+	// Middleware is a list of IDs of the beans of type *mux.MiddlewareFunc, wrapping the handler in the given order.
+	Middleware []string
+	// Server is a name of the server serving the route. Empty for the default server.
+	Server string
+	// Qualifiers maps zero-based indices of the handler method parameters to the IDs of the beans to inject.
+	Qualifiers map[int]string
+	// Summary is a short summary of the route, for the documentation.
+	Summary string
+	// Description is a verbose description of the route, for the documentation.
+	Description string
+	// Tags is a list of tags grouping the routes in the documentation.
+	Tags []string
+	// Deprecated flag marks the route as deprecated in the documentation.
+	Deprecated bool
+}
+
+func registerControllerHandlers(router *mux.Router, groupRouters map[string]*mux.Router, serverName string, beanID string, beanType reflect.Type) error {
+	controller, resolveController, err := beanResolver(beanID, beanType)
+	if err != nil {
+		return err
+	}
+	for _, routeSpec := range controller.(Controller).Routes() {
+		if routeSpec.Server != serverName {
+			continue
+		}
+		groupRouter, err := getGroupRouter(router, groupRouters, routeSpec.Group)
+		if err != nil {
+			return err
+		}
+		route, err := configureRoute(groupRouter.Name(beanID+"."+routeSpec.HandlerFuncName), routeSpec)
+		if err != nil {
+			return err
+		}
+		handler, err := createHandler(beanType, routeSpec.HandlerFuncName, routeSpec.Qualifiers, resolveController)
+		if err != nil {
+			return err
+		}
+		handler, err = applyMiddleware(handler, routeSpec.Middleware)
+		if err != nil {
+			return err
+		}
+		route.Handler(handler)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+)
+
+type controller1 struct {
+}
+
+func (c *controller1) Routes() []RouteSpec {
+	return []RouteSpec{
+		{HandlerFuncName: "Get", Methods: []string{"GET"}, Path: "/controller1/{id:[0-9]+}"},
+		{HandlerFuncName: "Create", Methods: []string{"POST"}, Path: "/controller1"},
+	}
+}
+
+func (c *controller1) Get(pathParams map[string]string) string {
+	return "get " + pathParams["id"]
+}
+
+func (c *controller1) Create(body string) (int, string) {
+	return http.StatusCreated, "create " + body
+}
+
+func (suite *TestSuite) TestController1() {
+	response, err := http.Get(server.URL + "/controller1/42")
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "get 42", string(all))
+	response, err = http.Post(server.URL+"/controller1", "", bytes.NewBufferString("test"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusCreated, response.StatusCode)
+	all, err = ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "create test", string(all))
+}
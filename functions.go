@@ -0,0 +1,186 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"encoding"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// functionEndpoint is an endpoint registered as a function. If handler is set, it's used as is, while handlerFunc only
+// describes its signature.
+type functionEndpoint struct {
+	routeSpec   RouteSpec
+	handlerFunc interface{}
+	handler     http.Handler
+}
+
+var functionEndpoints []functionEndpoint
+
+// Handle function registers a plain function as an endpoint. Pattern is a path optionally preceded by comma-separated
+// HTTP-methods and a space, e.g. "GET /users/{id}". The function can have any signature supported by the Endpoint
+// handler methods, and the path variables are bound to its scalar parameters in order, e.g. func(id int) (*User, error).
+// Function endpoints are registered by the routers created afterwards.
+func Handle(pattern string, handlerFunc interface{}) {
+	var routeSpec RouteSpec
+	if methods, path, ok := strings.Cut(strings.TrimSpace(pattern), " "); ok {
+		routeSpec.Methods = splitList(methods)
+		routeSpec.Path = strings.TrimSpace(path)
+	} else {
+		routeSpec.Path = methods
+	}
+	HandleRoute(routeSpec, handlerFunc)
+}
+
+// HandleRoute function registers a plain function as an endpoint served according to the route specification.
+// HandlerFuncName of the specification, if set, is used as the route name.
+func HandleRoute(routeSpec RouteSpec, handlerFunc interface{}) {
+	functionEndpoints = append(functionEndpoints, functionEndpoint{routeSpec: routeSpec, handlerFunc: handlerFunc})
+}
+
+func registerFunctionHandlers(router *mux.Router, groupRouters map[string]*mux.Router, serverName string) error {
+	for _, functionEndpoint := range functionEndpoints {
+		routeSpec := functionEndpoint.routeSpec
+		if routeSpec.Server != serverName {
+			continue
+		}
+		groupRouter, err := getGroupRouter(router, groupRouters, routeSpec.Group)
+		if err != nil {
+			return err
+		}
+		route := groupRouter.NewRoute()
+		if routeSpec.HandlerFuncName != "" {
+			route = route.Name(routeSpec.HandlerFuncName)
+		}
+		route, err = configureRoute(route, routeSpec)
+		if err != nil {
+			return err
+		}
+		handler := functionEndpoint.handler
+		if handler == nil {
+			handlerFunc := reflect.ValueOf(functionEndpoint.handlerFunc)
+			if handlerFunc.Kind() != reflect.Func {
+				return errors.New("handler is not a function: " + routeSpec.Path)
+			}
+			pathTemplate, _ := route.GetPathTemplate()
+			pathVariables, err := pathVariableNames(pathTemplate)
+			if err != nil {
+				return err
+			}
+			if handler, err = newHandler(handlerFunc, routeSpec.Qualifiers, pathVariables, nil); err != nil {
+				return err
+			}
+		}
+		handler, err = applyMiddleware(handler, routeSpec.Middleware)
+		if err != nil {
+			return err
+		}
+		route.Handler(handler)
+	}
+	return nil
+}
+
+// pathVariableNames function returns the names of the variables of the path template, e.g. "id" for "/users/{id:[0-9]+}".
+func pathVariableNames(pathTemplate string) ([]string, error) {
+	var names []string
+	for i := 0; i < len(pathTemplate); i++ {
+		if pathTemplate[i] != '{' {
+			continue
+		}
+		end, err := variableEnd(pathTemplate, i)
+		if err != nil {
+			return nil, err
+		}
+		name, _, _ := strings.Cut(pathTemplate[i+1:end], ":")
+		names = append(names, name)
+		i = end
+	}
+	return names, nil
+}
+
+// pathArguments function maps the indices of the handler function arguments to the names of the path variables bound to
+// them: the variables are bound in order to the arguments of scalar types (strings, booleans and numbers) or types
+// implementing encoding.TextUnmarshaler, skipping the injected ones. The rest of such arguments are request bodies.
+func pathArguments(handlerFuncType reflect.Type, firstArgument int, pathVariables []string, injectedArguments map[int]string) map[int]string {
+	arguments := make(map[int]string)
+	for i := firstArgument; i < handlerFuncType.NumIn() && len(arguments) < len(pathVariables); i++ {
+		if _, ok := injectedArguments[i]; ok || !bindable(handlerFuncType.In(i)) {
+			continue
+		}
+		arguments[i] = pathVariables[len(arguments)]
+	}
+	return arguments
+}
+
+func bindable(argument reflect.Type) bool {
+	if argument.Kind() == reflect.String {
+		return true
+	}
+	if builtinArgumentTypes[argument] {
+		return false
+	}
+	if reflect.PtrTo(argument).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+		return true
+	}
+	switch argument.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// pathValue function converts the value of the path variable to the argument type.
+func pathValue(argument reflect.Type, value string) (reflect.Value, error) {
+	result := reflect.New(argument)
+	if textUnmarshaler, ok := result.Interface().(encoding.TextUnmarshaler); ok {
+		return result.Elem(), textUnmarshaler.UnmarshalText([]byte(value))
+	}
+	result = result.Elem()
+	switch argument.Kind() {
+	case reflect.String:
+		result.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return result, err
+		}
+		result.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, argument.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, argument.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, argument.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetFloat(parsed)
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type functionResponse struct {
+	ID       string
+	Greeting string
+}
+
+func function1(vars map[string]string, greeter *testGreeter) (*functionResponse, error) {
+	if vars["id"] == "0" {
+		return nil, errors.New("not found")
+	}
+	return &functionResponse{ID: vars["id"], Greeting: greeter.greeting}, nil
+}
+
+func function2(body string) string {
+	return strings.ToUpper(body)
+}
+
+func function3(id int) (*functionResponse, error) {
+	return &functionResponse{ID: strconv.Itoa(id + 1)}, nil
+}
+
+func function4(name string, version uint8, body string) string {
+	return name + " " + strconv.Itoa(int(version)) + " " + body
+}
+
+func (suite *TestSuite) TestFunction1() {
+	response, err := http.Get(server.URL + "/function1/42")
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), `{"ID":"42","Greeting":"hello"}`, string(all))
+	response, err = http.Get(server.URL + "/function1/0")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusInternalServerError, response.StatusCode)
+	response, err = http.Post(server.URL+"/function1/42", "text/plain", nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusMethodNotAllowed, response.StatusCode)
+}
+
+func (suite *TestSuite) TestFunction2() {
+	response, err := http.Post(server.URL+"/function2", "text/plain", strings.NewReader("test"))
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "TEST", string(all))
+}
+
+func (suite *TestSuite) TestFunction3() {
+	response, err := http.Get(server.URL + "/function3/42")
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), `{"ID":"43","Greeting":""}`, string(all))
+	response, err = http.Get(server.URL + "/function3/abc")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, response.StatusCode)
+}
+
+func (suite *TestSuite) TestFunction4() {
+	response, err := http.Post(server.URL+"/function4/app/7", "text/plain", strings.NewReader("body"))
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "app 7 body", string(all))
+	response, err = http.Post(server.URL+"/function4/app/300", "text/plain", strings.NewReader("body"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, response.StatusCode)
+}
+
+func (suite *TestSuite) TestPathArguments() {
+	handlerFuncType := reflect.TypeOf(func(context.Context, *testGreeter, string, time.Time, int, []byte) {})
+	assert.Equal(suite.T(), map[int]string{2: "a", 3: "b", 4: "c"},
+		pathArguments(handlerFuncType, 0, []string{"a", "b", "c", "d"}, map[int]string{1: "testGreeter"}))
+	assert.Equal(suite.T(), map[int]string{2: "a"}, pathArguments(handlerFuncType, 0, []string{"a"}, nil))
+	names, err := pathVariableNames("/users/{id:[0-9]{3}}/{name}")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"id", "name"}, names)
+}
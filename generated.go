@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"encoding"
+	"github.com/goioc/di"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// HandlerFactory is a function creating http handler that calls the handler method of the bean resolved per request
+// without reflection. Such factories are generated by cmd/webgen.
+type HandlerFactory func(resolveBean func(*http.Request) interface{}) http.Handler
+
+type handlerFactoryKey struct {
+	beanType        reflect.Type
+	handlerFuncName string
+}
+
+var (
+	handlerFactoriesLock sync.RWMutex
+	handlerFactories     = make(map[handlerFactoryKey]HandlerFactory)
+)
+
+// RegisterHandlerFactory function registers the factory of the handler for the method of the bean type (pointer to the
+// structure). The routers prefer registered factories to the reflection-based handlers. Normally called from the code
+// generated by cmd/webgen.
+func RegisterHandlerFactory(beanType reflect.Type, handlerFuncName string, handlerFactory HandlerFactory) {
+	handlerFactoriesLock.Lock()
+	defer handlerFactoriesLock.Unlock()
+	handlerFactories[handlerFactoryKey{beanType: beanType, handlerFuncName: handlerFuncName}] = handlerFactory
+}
+
+func getHandlerFactory(beanType reflect.Type, handlerFuncName string) (HandlerFactory, bool) {
+	handlerFactoriesLock.RLock()
+	defer handlerFactoriesLock.RUnlock()
+	handlerFactory, ok := handlerFactories[handlerFactoryKey{beanType: beanType, handlerFuncName: handlerFuncName}]
+	return handlerFactory, ok
+}
+
+// ReadBody function reads the request body, panicking on error. Used by the generated handlers.
+func ReadBody(r *http.Request) []byte {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		panic(err)
+	}
+	return body
+}
+
+// DecodeBody function decodes the request body to v (pointer) the same way as the reflection-based handlers do (see
+// decodeObject). Panics on error. Used by the generated handlers.
+func DecodeBody(r *http.Request, v interface{}) {
+	if err := decodeObject(ReadBody(r), v); err != nil {
+		panic(err)
+	}
+}
+
+// WriteObject function writes v to the response with the status code (unless it's 0) the same way as the
+// reflection-based handlers do (see encodeObject). Content-Type header is set if the serializer implements ContentTyper
+// and the header is not set yet. Panics on error. Used by the generated handlers.
+func WriteObject(w http.ResponseWriter, statusCode int, v interface{}) {
+	body, contentType, err := encodeObject(v)
+	if err != nil {
+		panic(err)
+	}
+	if _, ok := w.Header()["Content-Type"]; !ok && contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	if statusCode != 0 {
+		w.WriteHeader(statusCode)
+	}
+	if _, err = w.Write(body); err != nil {
+		panic(err)
+	}
+}
+
+// decodeObject function decodes the data to v (pointer) using encoding.BinaryUnmarshaler, encoding.TextUnmarshaler or
+// GoiocSerializer bean, in this order.
+func decodeObject(data []byte, v interface{}) error {
+	switch value := v.(type) {
+	case encoding.BinaryUnmarshaler:
+		return value.UnmarshalBinary(data)
+	case encoding.TextUnmarshaler:
+		return value.UnmarshalText(data)
+	default:
+		return serializer().Deserialize(data, v)
+	}
+}
+
+// encodeObject function encodes v using encoding.BinaryMarshaler, encoding.TextMarshaler or GoiocSerializer bean, in
+// this order. The marshalers with pointer receivers are used for non-pointer values as well. The media type is returned
+// only for the serializer implementing ContentTyper.
+func encodeObject(v interface{}) ([]byte, string, error) {
+	marshaler := v
+	if value := reflect.ValueOf(v); value.IsValid() && value.Kind() != reflect.Ptr {
+		pointer := reflect.New(value.Type())
+		pointer.Elem().Set(value)
+		marshaler = pointer.Interface()
+	}
+	switch value := marshaler.(type) {
+	case encoding.BinaryMarshaler:
+		body, err := value.MarshalBinary()
+		return body, "", err
+	case encoding.TextMarshaler:
+		body, err := value.MarshalText()
+		return body, "", err
+	default:
+		webSerializer := serializer()
+		body, err := webSerializer.Serialize(v)
+		contentType := ""
+		if contentTyper, ok := webSerializer.(ContentTyper); ok {
+			contentType = contentTyper.ContentType()
+		}
+		return body, contentType, err
+	}
+}
+
+func serializer() Serializer {
+	webResponseSerializer, err := di.GetInstanceSafe(GoiocSerializer)
+	if err != nil {
+		panic(err)
+	}
+	return webResponseSerializer.(Serializer)
+}
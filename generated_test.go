@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+type endpoint33 struct {
+	method interface{} `web.methods:"POST"`
+	path   interface{} `web.path:"/endpoint33"`
+}
+
+func (e endpoint33) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint33) REST(body string) string {
+	return "reflection: " + body
+}
+
+func endpoint33HandlerFactory(resolveBean func(*http.Request) interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bean := resolveBean(r).(*endpoint33)
+		res0 := bean.REST(string(ReadBody(r)))
+		if _, err := w.Write([]byte("generated " + res0)); err != nil {
+			panic(err)
+		}
+	})
+}
+
+func (suite *TestSuite) TestEndpoint33() {
+	response, err := http.Post(server.URL+"/endpoint33", "text/plain", strings.NewReader("test"))
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "generated reflection: test", string(all))
+}
+
+func (suite *TestSuite) TestWriteObject() {
+	recorder := httptest.NewRecorder()
+	WriteObject(recorder, http.StatusCreated, map[string]int{"a": 1})
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), `{"a":1}`, recorder.Body.String())
+	recorder = httptest.NewRecorder()
+	WriteObject(recorder, 0, textStruct{a: "text"})
+	assert.Equal(suite.T(), "text", recorder.Body.String())
+	assert.NotEqual(suite.T(), "application/json", recorder.Header().Get("Content-Type"))
+	recorder = httptest.NewRecorder()
+	WriteObject(recorder, 0, &binaryStruct{a: "binary"})
+	assert.Equal(suite.T(), "binary", recorder.Body.String())
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":2}`))
+	var body map[string]int
+	DecodeBody(request, &body)
+	assert.Equal(suite.T(), map[string]int{"a": 2}, body)
+}
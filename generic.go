@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"errors"
+	"github.com/goioc/di"
+	"github.com/goioc/web/openapi"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+// JSON function registers a type-safe endpoint: request body is decoded to Req (left zero if the body is empty) and the
+// returned Resp is encoded with GoiocSerializer bean (JSON by default), like the bodies of the other endpoints. Bodies
+// that can't be decoded are rejected with 400 Bad Request and ValidationResponse, the same way the validation
+// middleware does; non-nil error results in 500 Internal Server Error. Unlike the reflection-based endpoints, the
+// handler is bound at compile time. Request-scoped beans can be obtained from the
+// context with Bean, path variables with Vars and the rest of the request (query, headers) with Request.
+func JSON[Req, Resp any](method, path string, handlerFunc func(ctx context.Context, req Req) (Resp, error)) {
+	JSONRoute(RouteSpec{Methods: []string{method}, Path: path}, handlerFunc)
+}
+
+// JSONRoute function registers a type-safe endpoint (see JSON) served according to the route specification.
+func JSONRoute[Req, Resp any](routeSpec RouteSpec, handlerFunc func(ctx context.Context, req Req) (Resp, error)) {
+	functionEndpoints = append(functionEndpoints, functionEndpoint{
+		routeSpec:   routeSpec,
+		handlerFunc: handlerFunc,
+		handler:     jsonHandler(handlerFunc),
+	})
+}
+
+type requestKey struct{}
+
+// Request function returns the HTTP request served by the type-safe endpoint, or nil if the context doesn't belong to
+// such request.
+func Request(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(requestKey{}).(*http.Request)
+	return r
+}
+
+// Vars function returns the path variables of the request served by the type-safe endpoint, or nil if the context
+// doesn't belong to such request.
+func Vars(ctx context.Context) map[string]string {
+	if r := Request(ctx); r != nil {
+		return mux.Vars(r)
+	}
+	return nil
+}
+
+// Bean function returns the bean with the given ID as T. Request-scoped beans are taken from the context populated by
+// di.Middleware, others are obtained from the container.
+func Bean[T any](ctx context.Context, beanID string) (T, error) {
+	var bean T
+	var instance interface{}
+	if di.GetBeanScopes()[beanID] == di.Request {
+		instance = ctx.Value(di.BeanKey(beanID))
+		if instance == nil {
+			return bean, errors.New("request-scoped bean is not found in the context: " + beanID)
+		}
+	} else {
+		var err error
+		if instance, err = di.GetInstanceSafe(beanID); err != nil {
+			return bean, err
+		}
+	}
+	bean, ok := instance.(T)
+	if !ok {
+		return bean, errors.New("bean has unexpected type: " + beanID)
+	}
+	return bean, nil
+}
+
+func jsonHandler[Req, Resp any](handlerFunc func(ctx context.Context, req Req) (Resp, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webSerializer := serializer()
+		var req Req
+		if body := ReadBody(r); len(body) > 0 {
+			if err := webSerializer.Deserialize(body, &req); err != nil {
+				writeValidationResponse(w, http.StatusBadRequest, openapi.ValidationErrors{{In: "body", Message: err.Error()}})
+				return
+			}
+		}
+		resp, err := handlerFunc(context.WithValue(r.Context(), requestKey{}, r), req)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		response, err := webSerializer.Serialize(resp)
+		if err != nil {
+			panic(err)
+		}
+		if contentTyper, ok := webSerializer.(ContentTyper); ok {
+			w.Header().Set("Content-Type", contentTyper.ContentType())
+		}
+		if _, err := w.Write(response); err != nil {
+			panic(err)
+		}
+	})
+}
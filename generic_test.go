@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+type jsonRequest struct {
+	Name string
+}
+
+type jsonResponse struct {
+	Greeting   string
+	Statements int
+}
+
+func json1(ctx context.Context, req jsonRequest) (*jsonResponse, error) {
+	if req.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	greeter, err := Bean[*testGreeter](ctx, "testGreeter")
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := Bean[*testTransaction](ctx, "transaction1")
+	if err != nil {
+		return nil, err
+	}
+	transaction.statements++
+	return &jsonResponse{Greeting: greeter.greeting + ", " + req.Name, Statements: transaction.statements}, nil
+}
+
+func (suite *TestSuite) TestJSON1() {
+	response, err := http.Post(server.URL+"/json1", "application/json", strings.NewReader(`{"Name":"John"}`))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "application/json", response.Header.Get("Content-Type"))
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), `{"Greeting":"hello, John","Statements":1}`, string(all))
+	response, err = http.Post(server.URL+"/json1", "application/json", strings.NewReader(`{`))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, response.StatusCode)
+	assert.Equal(suite.T(), "application/json", response.Header.Get("Content-Type"))
+	all, err = ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), `{"message":"Bad Request","errors":[{"in":"body","message":"unexpected end of JSON input"}]}`,
+		string(all))
+	response, err = http.Post(server.URL+"/json1", "application/json", nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusInternalServerError, response.StatusCode)
+}
+
+func json2(ctx context.Context, req jsonRequest) (*jsonResponse, error) {
+	r := Request(ctx)
+	return &jsonResponse{Greeting: Vars(ctx)["id"] + ", " + req.Name + ", " + r.URL.Query().Get("lang") + ", " +
+		r.Header.Get("X-Greeting")}, nil
+}
+
+func (suite *TestSuite) TestJSON2() {
+	request, err := http.NewRequest(http.MethodPut, server.URL+"/json2/42?lang=en", strings.NewReader(`{"Name":"John"}`))
+	assert.NoError(suite.T(), err)
+	request.Header.Set("X-Greeting", "hello")
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), `{"Greeting":"42, John, en, hello","Statements":0}`, string(all))
+}
+
+func (suite *TestSuite) TestVars() {
+	assert.Nil(suite.T(), Request(context.Background()))
+	assert.Nil(suite.T(), Vars(context.Background()))
+}
+
+func (suite *TestSuite) TestBean() {
+	_, err := Bean[*testTransaction](context.Background(), "transaction1")
+	assert.EqualError(suite.T(), err, "request-scoped bean is not found in the context: transaction1")
+	_, err = Bean[*testTransaction](context.Background(), "testGreeter")
+	assert.EqualError(suite.T(), err, "bean has unexpected type: testGreeter")
+}
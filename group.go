@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"errors"
+	"github.com/goioc/di"
+	"github.com/gorilla/mux"
+)
+
+// Group is a bean representing a group of routes sharing the same path prefix, middleware and constraints. Endpoints
+// join the group using the `web.group` tag with the ID of the Group bean.
+type Group struct {
+	// Prefix is a path prefix of all the routes in the group, e.g. "/api/v1".
+	Prefix string
+	// Headers is a list of key-value pairs of the request headers, required for all the routes in the group.
+	Headers []string
+	// Matcher is an ID of the bean of type *mux.MatcherFunc, applied to all the routes in the group.
+	Matcher string
+	// Middleware is a list of middleware functions applied to all the routes in the group.
+	Middleware []mux.MiddlewareFunc
+}
+
+func getGroupRouter(router *mux.Router, groupRouters map[string]*mux.Router, groupID string) (*mux.Router, error) {
+	if groupID == "" {
+		return router, nil
+	}
+	if groupRouter, ok := groupRouters[groupID]; ok {
+		return groupRouter, nil
+	}
+	instance, err := di.GetInstanceSafe(groupID)
+	if err != nil {
+		return nil, err
+	}
+	group, ok := instance.(*Group)
+	if !ok {
+		return nil, errors.New("bean is not a group: " + groupID)
+	}
+	route, err := configureRoute(router.PathPrefix(group.Prefix), RouteSpec{
+		Headers: group.Headers,
+		Matcher: group.Matcher,
+	})
+	if err != nil {
+		return nil, err
+	}
+	groupRouter := route.Subrouter()
+	groupRouter.Use(group.Middleware...)
+	groupRouters[groupID] = groupRouter
+	return groupRouter, nil
+}
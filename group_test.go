@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+)
+
+var group1 = &Group{
+	Prefix: "/group1",
+	Middleware: []mux.MiddlewareFunc{func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("group", "group1")
+			next.ServeHTTP(w, r)
+		})
+	}},
+}
+
+type endpoint26 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint26"`
+	group  interface{} `web.group:"group1"`
+}
+
+func (e endpoint26) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint26) REST() string {
+	return "test"
+}
+
+func (suite *TestSuite) TestEndpoint26() {
+	response, err := http.Get(server.URL + "/endpoint26")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNotFound, response.StatusCode)
+	response, err = http.Get(server.URL + "/group1/endpoint26")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "group1", response.Header.Get("group"))
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test", string(all))
+}
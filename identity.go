@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// ClientIdentity is an identity of the client, verified by mutual TLS. Can be used as an argument of the endpoint's
+// method (as *ClientIdentity, nil if the client wasn't verified).
+type ClientIdentity struct {
+	// Subject is a subject of the client certificate.
+	Subject pkix.Name
+	// DNSNames are DNS subject alternative names of the client certificate.
+	DNSNames []string
+	// EmailAddresses are email subject alternative names of the client certificate.
+	EmailAddresses []string
+	// IPAddresses are IP subject alternative names of the client certificate.
+	IPAddresses []net.IP
+	// URIs are URI subject alternative names of the client certificate (e.g. SPIFFE IDs).
+	URIs []*url.URL
+	// Certificate is the client certificate itself.
+	Certificate *x509.Certificate
+}
+
+// RequestClientCertificate function returns the verified client certificate of the request, or nil.
+func RequestClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+// RequestClientIdentity function returns the identity of the client verified by the request's TLS connection, or nil.
+func RequestClientIdentity(r *http.Request) *ClientIdentity {
+	certificate := RequestClientCertificate(r)
+	if certificate == nil {
+		return nil
+	}
+	return &ClientIdentity{
+		Subject:        certificate.Subject,
+		DNSNames:       certificate.DNSNames,
+		EmailAddresses: certificate.EmailAddresses,
+		IPAddresses:    certificate.IPAddresses,
+		URIs:           certificate.URIs,
+		Certificate:    certificate,
+	}
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	bundle, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(bundle) {
+		return nil, errors.New("no certificates found in " + file)
+	}
+	return certPool, nil
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type endpoint29 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint29"`
+}
+
+func (e endpoint29) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint29) REST(identity *ClientIdentity, certificate *x509.Certificate) string {
+	if identity == nil || certificate == nil {
+		return "anonymous"
+	}
+	return identity.Subject.CommonName + " " + identity.URIs[0].String()
+}
+
+// generateClientCertificate creates a CA and a client certificate signed by it, returning the CA in PEM.
+func generateClientCertificate(commonName string, uri *url.URL) ([]byte, tls.Certificate) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		panic(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		URIs:         []*url.URL{uri},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}
+}
+
+func (suite *TestSuite) TestEndpoint29() {
+	response, err := http.Get(server.URL + "/endpoint29")
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "anonymous", string(all))
+}
+
+func (suite *TestSuite) TestMutualTLS() {
+	dir := suite.T().TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "ca.pem")
+	writeCertificate(certFile, keyFile, "server", time.Now())
+	uri, err := url.Parse("spiffe://example.org/client")
+	assert.NoError(suite.T(), err)
+	caPEM, clientCertificate := generateClientCertificate("client", uri)
+	assert.NoError(suite.T(), os.WriteFile(caFile, caPEM, 0600))
+	addr := freeAddr()
+	testServer := NewServer(addr)
+	testServer.ClientCAFile = caFile
+	errs := make(chan error, 1)
+	go func() {
+		errs <- testServer.ListenAndServeTLS(certFile, keyFile)
+	}()
+	client := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{clientCertificate},
+	}}}
+	var response *http.Response
+	for i := 0; i < 100; i++ {
+		if response, err = client.Get("https://" + addr + "/endpoint29"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "client spiffe://example.org/client", string(all))
+	anonymousClient := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	_, err = anonymousClient.Get("https://" + addr + "/endpoint29")
+	assert.Error(suite.T(), err)
+	assert.NoError(suite.T(), testServer.Shutdown(context.Background()))
+	assert.NoError(suite.T(), <-errs)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"github.com/goioc/di"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// builtinArgumentTypes are the handler argument types resolved from the request itself.
+var builtinArgumentTypes = map[reflect.Type]bool{
+	reflect.TypeOf((*context.Context)(nil)).Elem():     true,
+	reflect.TypeOf((*http.ResponseWriter)(nil)).Elem(): true,
+	reflect.TypeOf((*http.Request)(nil)):               true,
+	reflect.TypeOf((*http.Header)(nil)).Elem():         true,
+	reflect.TypeOf((*io.Reader)(nil)).Elem():           true,
+	reflect.TypeOf((*io.ReadCloser)(nil)).Elem():       true,
+	reflect.TypeOf((*[]byte)(nil)).Elem():              true,
+	reflect.TypeOf((*string)(nil)).Elem():              true,
+	reflect.TypeOf((map[string]string)(nil)):           true,
+	reflect.TypeOf((url.Values)(nil)):                  true,
+	reflect.TypeOf((*x509.Certificate)(nil)):           true,
+	reflect.TypeOf((*ClientIdentity)(nil)):             true,
+}
+
+// InjectByType is a qualifier marking the handler parameter to be resolved by type: from the bean of exactly this type
+// or, for non-empty interface parameters, implementing it. E.g. `web.qualifiers:"1:*"`.
+const InjectByType = "*"
+
+// parseQualifiers function parses the value of `web.qualifiers` tag: comma-separated list of "<index>:<beanID>" pairs,
+// where index is a zero-based position of the handler method parameter and beanID may be InjectByType.
+func parseQualifiers(value string) (map[int]string, error) {
+	qualifiers := make(map[int]string)
+	for _, pair := range strings.Split(value, ",") {
+		index, beanID, ok := strings.Cut(pair, ":")
+		beanID = strings.TrimSpace(beanID)
+		if !ok || beanID == "" {
+			return nil, errors.New("invalid qualifier, expected <index>:<beanID>: " + pair)
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(index))
+		if err != nil {
+			return nil, errors.New("invalid qualifier index: " + pair)
+		}
+		qualifiers[i] = beanID
+	}
+	return qualifiers, nil
+}
+
+// beanArguments function maps the indices of handler function arguments to the IDs of the beans to inject. Arguments
+// before firstArgument (i.e. the receiver of the method expression) are skipped. Only qualified parameters are
+// injected: by bean ID or, for InjectByType, by type. Unqualified parameters are resolved from the request as usual.
+func beanArguments(handlerFuncType reflect.Type, firstArgument int, qualifiers map[int]string) (map[int]string, error) {
+	beanTypes := di.GetBeanTypes()
+	arguments := make(map[int]string)
+	for index, beanID := range qualifiers {
+		if index < 0 || index >= handlerFuncType.NumIn()-firstArgument {
+			return nil, errors.New("qualifier index is out of range: " + strconv.Itoa(index))
+		}
+		argument := handlerFuncType.In(index + firstArgument)
+		if beanID == InjectByType {
+			var err error
+			if beanID, err = beanByType(beanTypes, argument); err != nil {
+				return nil, err
+			}
+		}
+		beanType, ok := beanTypes[beanID]
+		if !ok {
+			return nil, errors.New("qualified bean is not registered: " + beanID)
+		}
+		if !beanType.AssignableTo(argument) {
+			return nil, errors.New("bean " + beanID + " is not assignable to " + argument.String())
+		}
+		arguments[index+firstArgument] = beanID
+	}
+	return arguments, nil
+}
+
+// beanByType function returns the ID of the only bean of the argument type or implementing the argument interface.
+func beanByType(beanTypes map[string]reflect.Type, argument reflect.Type) (string, error) {
+	var candidates []string
+	for beanID, beanType := range beanTypes {
+		if beanType == argument || argument.Kind() == reflect.Interface && argument.NumMethod() > 0 && beanType.Implements(argument) {
+			candidates = append(candidates, beanID)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", errors.New("no bean matches argument of type " + argument.String())
+	case 1:
+		return candidates[0], nil
+	}
+	sort.Strings(candidates)
+	return "", errors.New("several beans match argument of type " + argument.String() + ", bean ID is required: " +
+		strings.Join(candidates, ", "))
+}
+
+// beanInstance function returns the instance of the bean for the request: request-scoped beans are taken from the
+// request context populated by di.Middleware, others are obtained from the container.
+func beanInstance(r *http.Request, beanID string) interface{} {
+	if di.GetBeanScopes()[beanID] != di.Request {
+		return di.GetInstance(beanID)
+	}
+	instance := r.Context().Value(di.BeanKey(beanID))
+	if instance == nil {
+		panic("request-scoped bean is not found in the context: " + beanID)
+	}
+	return instance
+}
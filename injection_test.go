@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+type testGreeter struct {
+	greeting string
+}
+
+type testTransaction struct {
+	scope      interface{} `di.scope:"request"`
+	statements int
+}
+
+type endpoint32 struct {
+	method     interface{} `web.methods:"GET"`
+	path       interface{} `web.path:"/endpoint32"`
+	qualifiers interface{} `web.qualifiers:"0:*,1:transaction2"`
+}
+
+func (e endpoint32) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint32) REST(greeter *testGreeter, transaction *testTransaction) string {
+	transaction.statements++
+	return greeter.greeting + " " + strconv.Itoa(transaction.statements)
+}
+
+func (suite *TestSuite) TestEndpoint32() {
+	for i := 0; i < 2; i++ {
+		response, err := http.Get(server.URL + "/endpoint32")
+		assert.NoError(suite.T(), err)
+		all, err := ioutil.ReadAll(response.Body)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), "hello 1", string(all))
+	}
+}
+
+func (suite *TestSuite) TestBeanArguments() {
+	handlerFuncType := reflect.TypeOf(func(string, *testTransaction) {})
+	arguments, err := beanArguments(handlerFuncType, 0, nil)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), arguments)
+	_, err = beanArguments(handlerFuncType, 0, map[int]string{1: InjectByType})
+	assert.EqualError(suite.T(), err, "several beans match argument of type *web.testTransaction, bean ID is required: transaction1, transaction2")
+	_, err = beanArguments(handlerFuncType, 0, map[int]string{1: "testGreeter"})
+	assert.EqualError(suite.T(), err, "bean testGreeter is not assignable to *web.testTransaction")
+	_, err = beanArguments(handlerFuncType, 0, map[int]string{0: InjectByType})
+	assert.EqualError(suite.T(), err, "no bean matches argument of type string")
+	arguments, err = beanArguments(reflect.TypeOf(func(*testGreeter) {}), 0, map[int]string{0: InjectByType})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[int]string{0: "testGreeter"}, arguments)
+	_, err = parseQualifiers("transaction1")
+	assert.Error(suite.T(), err)
+	qualifiers, err := parseQualifiers("0: transaction1, 1 :*")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[int]string{0: "transaction1", 1: InjectByType}, qualifiers)
+}
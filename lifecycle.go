@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"errors"
+	"github.com/goioc/di"
+	"github.com/sirupsen/logrus"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// OnStart is an interface marking beans that should be started together with the Server.
+type OnStart interface {
+	// OnStart method is called after the Server's listener is bound, but before it starts serving requests.
+	OnStart(ctx context.Context) error
+}
+
+// OnStop is an interface marking beans that should be stopped together with the Server.
+type OnStop interface {
+	// OnStop method is called after the Server has stopped serving requests (gracefully or not).
+	OnStop(ctx context.Context) error
+}
+
+// lifecycleBeans returns singleton beans implementing OnStart or OnStop, sorted by ID.
+func lifecycleBeans() ([]interface{}, error) {
+	onStartType := reflect.TypeOf((*OnStart)(nil)).Elem()
+	onStopType := reflect.TypeOf((*OnStop)(nil)).Elem()
+	beanScopes := di.GetBeanScopes()
+	var beanIDs []string
+	for beanID, beanType := range di.GetBeanTypes() {
+		if (beanType.Implements(onStartType) || beanType.Implements(onStopType)) && beanScopes[beanID] == di.Singleton {
+			beanIDs = append(beanIDs, beanID)
+		}
+	}
+	sort.Strings(beanIDs)
+	beans := make([]interface{}, 0, len(beanIDs))
+	for _, beanID := range beanIDs {
+		instance, err := di.GetInstanceSafe(beanID)
+		if err != nil {
+			return nil, err
+		}
+		beans = append(beans, instance)
+	}
+	return beans, nil
+}
+
+// startBeans starts the lifecycle beans (see startInOrder). All the beans are returned, so that they can be stopped
+// later.
+func startBeans(ctx context.Context, stopTimeout time.Duration) ([]interface{}, error) {
+	beans, err := lifecycleBeans()
+	if err != nil {
+		return nil, err
+	}
+	if err := startInOrder(ctx, beans, stopTimeout); err != nil {
+		return nil, err
+	}
+	return beans, nil
+}
+
+// startInOrder starts the beans one by one. If some bean fails to start, the ones started before are stopped and the
+// errors are returned. The beans are stopped with a fresh context bounded by stopTimeout, since the start context may
+// be the very one that has just expired.
+func startInOrder(ctx context.Context, beans []interface{}, stopTimeout time.Duration) error {
+	for i, bean := range beans {
+		onStart, ok := bean.(OnStart)
+		if !ok {
+			continue
+		}
+		if err := onStart.OnStart(ctx); err != nil {
+			stopCtx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+			defer cancel()
+			return errors.Join(err, stopBeans(stopCtx, beans[:i]))
+		}
+		logrus.WithField("bean", reflect.TypeOf(bean)).Trace("Bean started")
+	}
+	return nil
+}
+
+// stopBeans stops the beans in the reverse order, aggregating the errors.
+func stopBeans(ctx context.Context, beans []interface{}) error {
+	var errs []error
+	for i := len(beans) - 1; i >= 0; i-- {
+		onStop, ok := beans[i].(OnStop)
+		if !ok {
+			continue
+		}
+		if err := onStop.OnStop(ctx); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logrus.WithField("bean", reflect.TypeOf(beans[i])).Trace("Bean stopped")
+	}
+	return errors.Join(errs...)
+}
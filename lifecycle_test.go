@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"time"
+)
+
+type lifecycleBean struct {
+	started int32
+	stopped int32
+}
+
+func (lb *lifecycleBean) OnStart(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("no deadline")
+	}
+	atomic.AddInt32(&lb.started, 1)
+	return nil
+}
+
+func (lb *lifecycleBean) OnStop(ctx context.Context) error {
+	atomic.AddInt32(&lb.stopped, 1)
+	return nil
+}
+
+type failingLifecycleBean struct {
+	name string
+}
+
+func (flb *failingLifecycleBean) OnStop(ctx context.Context) error {
+	return errors.New(flb.name)
+}
+
+type contextLifecycleBean struct {
+	checkStart bool
+	stopErr    error
+}
+
+func (clb *contextLifecycleBean) OnStart(ctx context.Context) error {
+	if clb.checkStart {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (clb *contextLifecycleBean) OnStop(ctx context.Context) error {
+	clb.stopErr = ctx.Err()
+	return nil
+}
+
+var testLifecycleBean = new(lifecycleBean)
+
+func (suite *TestSuite) TestLifecycle() {
+	started, stopped := atomic.LoadInt32(&testLifecycleBean.started), atomic.LoadInt32(&testLifecycleBean.stopped)
+	addr := freeAddr()
+	testServer := NewServer(addr)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- testServer.ListenAndServe()
+	}()
+	_, err := waitForServer("http://" + addr + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), started+1, atomic.LoadInt32(&testLifecycleBean.started))
+	assert.Equal(suite.T(), stopped, atomic.LoadInt32(&testLifecycleBean.stopped))
+	assert.NoError(suite.T(), testServer.Shutdown(context.Background()))
+	assert.NoError(suite.T(), <-errs)
+	assert.Equal(suite.T(), stopped+1, atomic.LoadInt32(&testLifecycleBean.stopped))
+}
+
+func (suite *TestSuite) TestStartInOrderRollback() {
+	started := new(contextLifecycleBean)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := startInOrder(ctx, []interface{}{started, &contextLifecycleBean{checkStart: true}}, time.Second)
+	assert.ErrorIs(suite.T(), err, context.Canceled)
+	assert.NoError(suite.T(), started.stopErr)
+}
+
+func (suite *TestSuite) TestStopBeans() {
+	err := stopBeans(context.Background(), []interface{}{
+		&failingLifecycleBean{name: "first"},
+		&failingLifecycleBean{name: "second"},
+	})
+	assert.EqualError(suite.T(), err, "second\nfirst")
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation.
+const listenFDsStart = 3
+
+// UnixSocket is a configuration of the Unix domain socket to listen on.
+type UnixSocket struct {
+	// Path is a path of the socket file.
+	Path string
+	// Mode is a permission of the socket file. Left intact if zero.
+	Mode os.FileMode
+	// Owner is a name or a numeric ID of the user owning the socket file. Left intact if empty.
+	Owner string
+	// Group is a name or a numeric ID of the group owning the socket file. Left intact if empty.
+	Group string
+}
+
+// ListenUnix function creates a listener on the Unix domain socket. Stale socket file left by the previous process is
+// removed, but if the socket is still in use by someone, an error is returned. The socket file is removed when the
+// listener is closed.
+func ListenUnix(socket UnixSocket) (net.Listener, error) {
+	if err := removeStaleSocket(socket.Path); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", socket.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := configureSocket(socket); err != nil {
+		return nil, errors.Join(err, listener.Close())
+	}
+	return listener, nil
+}
+
+// SystemdListeners function returns listeners passed by systemd socket activation (see sd_listen_fds(3)) by their names
+// (FileDescriptorName= of the socket units, "LISTEN_FD_<fd>" for the unnamed ones), or nil if the process wasn't
+// socket-activated. Naming the sockets after the servers lets the result be remapped for ServeListeners. Duplicate names
+// result in an error. If an error occurs, the listeners created so far are closed.
+func SystemdListeners() (map[string]net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return nil, errors.New("invalid LISTEN_FDS value: " + os.Getenv("LISTEN_FDS"))
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for _, name := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		if err := os.Unsetenv(name); err != nil {
+			return nil, err
+		}
+	}
+	files := make([]*os.File, count)
+	for i := range files {
+		fd := listenFDsStart + i
+		files[i] = os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+	}
+	return fileListeners(files, names)
+}
+
+// fileListeners function creates the listeners from the files, naming them with the names (or the names of the files,
+// if missing). The files are closed. If an error occurs, the listeners created so far are closed as well.
+func fileListeners(files []*os.File, names []string) (map[string]net.Listener, error) {
+	listeners := make(map[string]net.Listener, len(files))
+	var errs []error
+	for i, file := range files {
+		if len(errs) > 0 {
+			errs = append(errs, file.Close())
+			continue
+		}
+		name := file.Name()
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		if _, ok := listeners[name]; ok {
+			errs = append(errs, errors.New("duplicate listener name: "+name), file.Close())
+			continue
+		}
+		listener, err := net.FileListener(file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			if listener != nil {
+				err = errors.Join(err, listener.Close())
+			}
+			errs = append(errs, err)
+			continue
+		}
+		listeners[name] = listener
+	}
+	if len(errs) > 0 {
+		for _, listener := range listeners {
+			errs = append(errs, listener.Close())
+		}
+		return nil, errors.Join(errs...)
+	}
+	return listeners, nil
+}
+
+func removeStaleSocket(path string) error {
+	fileInfo, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fileInfo.Mode()&os.ModeSocket == 0 {
+		return errors.New("file exists and is not a socket: " + path)
+	}
+	if connection, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = connection.Close()
+		return errors.New("socket is in use: " + path)
+	}
+	return os.Remove(path)
+}
+
+func configureSocket(socket UnixSocket) error {
+	if socket.Mode != 0 {
+		if err := os.Chmod(socket.Path, socket.Mode); err != nil {
+			return err
+		}
+	}
+	if socket.Owner == "" && socket.Group == "" {
+		return nil
+	}
+	uid, gid := -1, -1
+	if socket.Owner != "" {
+		owner, err := lookupID(socket.Owner, func(name string) (string, error) {
+			owner, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return owner.Uid, nil
+		})
+		if err != nil {
+			return err
+		}
+		uid = owner
+	}
+	if socket.Group != "" {
+		group, err := lookupID(socket.Group, func(name string) (string, error) {
+			group, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return group.Gid, nil
+		})
+		if err != nil {
+			return err
+		}
+		gid = group
+	}
+	return os.Chown(socket.Path, uid, gid)
+}
+
+func lookupID(nameOrID string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return id, nil
+	}
+	id, err := lookup(nameOrID)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
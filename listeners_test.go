@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func (suite *TestSuite) TestServeUnix() {
+	socketPath := filepath.Join(suite.T().TempDir(), "web.sock")
+	staleListener, err := net.Listen("unix", socketPath)
+	assert.NoError(suite.T(), err)
+	staleListener.(*net.UnixListener).SetUnlinkOnClose(false)
+	_, err = ListenUnix(UnixSocket{Path: socketPath})
+	assert.Error(suite.T(), err)
+	assert.NoError(suite.T(), staleListener.Close())
+	listener, err := ListenUnix(UnixSocket{Path: socketPath, Mode: 0600})
+	assert.NoError(suite.T(), err)
+	fileInfo, err := os.Stat(socketPath)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), os.FileMode(0600), fileInfo.Mode().Perm())
+	testServer := NewServer("")
+	errs := make(chan error, 1)
+	go func() {
+		errs <- testServer.Serve(listener)
+	}()
+	client := http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return new(net.Dialer).DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	response, err := client.Get("http://unix/endpoint1")
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test", string(all))
+	assert.NoError(suite.T(), testServer.Shutdown(context.Background()))
+	assert.NoError(suite.T(), <-errs)
+	_, err = os.Stat(socketPath)
+	assert.True(suite.T(), os.IsNotExist(err))
+}
+
+func (suite *TestSuite) TestSystemdListeners() {
+	listeners, err := SystemdListeners()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), listeners)
+	suite.T().Setenv("LISTEN_PID", "1")
+	suite.T().Setenv("LISTEN_FDS", "1")
+	listeners, err = SystemdListeners()
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), listeners)
+}
+
+func (suite *TestSuite) TestFileListeners() {
+	listenerFile := func() *os.File {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(suite.T(), err)
+		file, err := listener.(*net.TCPListener).File()
+		assert.NoError(suite.T(), err)
+		assert.NoError(suite.T(), listener.Close())
+		return file
+	}
+	first, second := listenerFile(), listenerFile()
+	listeners, err := fileListeners([]*os.File{first, second}, []string{"http"})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), listeners, 2)
+	assert.Contains(suite.T(), listeners, "http")
+	assert.Contains(suite.T(), listeners, second.Name())
+	for _, listener := range listeners {
+		assert.NoError(suite.T(), listener.Close())
+	}
+	first, second = listenerFile(), listenerFile()
+	_, err = fileListeners([]*os.File{first, second}, []string{"http", "http"})
+	assert.EqualError(suite.T(), err, "duplicate listener name: http")
+	assert.Error(suite.T(), second.Close())
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"errors"
+	"github.com/goioc/di"
+	"github.com/gorilla/mux"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+var middlewareFunctionsInternal []mux.MiddlewareFunc
+
+// Middleware is an interface representing middleware bean. Such beans are applied to all the endpoints in the
+// ascending order of Order(), after the middleware registered with Use.
+type Middleware interface {
+	// Middleware method should wrap the next handler.
+	Middleware(next http.Handler) http.Handler
+	// Order method should return the position of the middleware in the chain: the lower, the outer.
+	Order() int
+}
+
+// Use function registers middleware. Subsequent calls append middleware to the chain in the order of registration.
+func Use(middlewareFunctions ...mux.MiddlewareFunc) {
+	middlewareFunctionsInternal = append(middlewareFunctionsInternal, middlewareFunctions...)
+}
+
+func middlewareBeans() ([]mux.MiddlewareFunc, error) {
+	_, middlewares, err := orderedMiddlewareBeans()
+	if err != nil {
+		return nil, err
+	}
+	middlewareFunctions := make([]mux.MiddlewareFunc, 0, len(middlewares))
+	for _, middleware := range middlewares {
+		middlewareFunctions = append(middlewareFunctions, middleware.Middleware)
+	}
+	return middlewareFunctions, nil
+}
+
+// orderedMiddlewareBeans returns the Middleware beans and their IDs in the order of the chain.
+func orderedMiddlewareBeans() ([]string, []Middleware, error) {
+	middlewareType := reflect.TypeOf((*Middleware)(nil)).Elem()
+	beanScopes := di.GetBeanScopes()
+	var beanIDs []string
+	for beanID, beanType := range di.GetBeanTypes() {
+		if beanType.Implements(middlewareType) && beanScopes[beanID] == di.Singleton {
+			beanIDs = append(beanIDs, beanID)
+		}
+	}
+	sort.Strings(beanIDs)
+	middlewares := make([]Middleware, 0, len(beanIDs))
+	for _, beanID := range beanIDs {
+		instance, err := di.GetInstanceSafe(beanID)
+		if err != nil {
+			return nil, nil, err
+		}
+		middlewares = append(middlewares, instance.(Middleware))
+	}
+	sort.Stable(middlewareOrder{beanIDs: beanIDs, middlewares: middlewares})
+	return beanIDs, middlewares, nil
+}
+
+// middlewareOrder sorts the Middleware beans together with their IDs.
+type middlewareOrder struct {
+	beanIDs     []string
+	middlewares []Middleware
+}
+
+func (mo middlewareOrder) Len() int {
+	return len(mo.middlewares)
+}
+
+func (mo middlewareOrder) Less(i, j int) bool {
+	return mo.middlewares[i].Order() < mo.middlewares[j].Order()
+}
+
+func (mo middlewareOrder) Swap(i, j int) {
+	mo.beanIDs[i], mo.beanIDs[j] = mo.beanIDs[j], mo.beanIDs[i]
+	mo.middlewares[i], mo.middlewares[j] = mo.middlewares[j], mo.middlewares[i]
+}
+
+// applyMiddleware wraps the handler with the middleware beans, so that the first one in the list is the outermost.
+func applyMiddleware(handler http.Handler, middlewareBeanIDs []string) (http.Handler, error) {
+	for i := len(middlewareBeanIDs) - 1; i >= 0; i-- {
+		instance, err := di.GetInstanceSafe(middlewareBeanIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		middlewareFunction, ok := instance.(*mux.MiddlewareFunc)
+		if !ok {
+			return nil, errors.New("bean is not a middleware function: " + middlewareBeanIDs[i])
+		}
+		handler = (*middlewareFunction)(handler)
+	}
+	return handler, nil
+}
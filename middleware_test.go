@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+func orderMiddleware(value string) *mux.MiddlewareFunc {
+	middlewareFunction := mux.MiddlewareFunc(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("order", value)
+			next.ServeHTTP(w, r)
+		})
+	})
+	return &middlewareFunction
+}
+
+type orderedMiddleware struct {
+	order int
+}
+
+func (om *orderedMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("bean-order", strconv.Itoa(om.order))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (om *orderedMiddleware) Order() int {
+	return om.order
+}
+
+type endpoint27 struct {
+	method     interface{} `web.methods:"GET"`
+	path       interface{} `web.path:"/endpoint27"`
+	middleware interface{} `web.middleware:"middleware1, middleware2"`
+}
+
+func (e endpoint27) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint27) REST() string {
+	return "test"
+}
+
+func (suite *TestSuite) TestEndpoint27() {
+	response, err := http.Get(server.URL + "/endpoint27")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"1", "2"}, response.Header.Values("order"))
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test", string(all))
+	response, err = http.Get(server.URL + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), response.Header.Values("order"))
+}
+
+func (suite *TestSuite) TestMiddlewareBeans() {
+	response, err := http.Get(server.URL + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"1", "2"}, response.Header.Values("bean-order"))
+	assert.Equal(suite.T(), "second", response.Header.Get("use"))
+}
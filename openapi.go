@@ -0,0 +1,597 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"github.com/goioc/di"
+	"github.com/goioc/web/openapi"
+	"github.com/gorilla/mux"
+	htmlTemplate "html/template"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	textTemplate "text/template"
+	"time"
+)
+
+// GoiocOpenAPI is an ID for OpenAPIConfig bean. It's not registered by default: register your own instance to serve
+// the OpenAPI document describing the routes of the server.
+const GoiocOpenAPI = "goiocOpenAPI"
+
+// DefaultOpenAPIPath is a default path the OpenAPI document is served at.
+const DefaultOpenAPIPath = "/openapi.json"
+
+// DefaultDocsPath is a default path the interactive documentation page is served at.
+const DefaultDocsPath = "/docs"
+
+// OpenAPIConfig is a configuration of the OpenAPI document served by the router.
+type OpenAPIConfig struct {
+	// Info is a metadata of the API.
+	Info openapi.Info
+	// Servers are the servers the API is available at.
+	Servers []openapi.Server
+	// Path is a path the document is served at. DefaultOpenAPIPath is used if empty.
+	Path string
+	// Server is a name of the server serving the document (and described by it). Empty for the default server.
+	Server string
+	// Docs is a function creating the handler of the interactive documentation page for the URL of the document, e.g.
+	// swaggerui.Handler. The page is not served if nil.
+	Docs func(specURL string) http.Handler
+	// DocsPath is a path the documentation page is served at. DefaultDocsPath is used if empty.
+	DocsPath string
+	// DocsEnabled is a function deciding whether to serve the documentation page, e.g. to restrict it to non-production
+	// environments. The page is served if nil.
+	DocsEnabled func() bool
+}
+
+// routeDescription describes the route and the signature of its handler.
+type routeDescription struct {
+	name            string
+	beanID          string
+	routeSpec       RouteSpec
+	group           *Group
+	handler         string
+	handlerFuncType reflect.Type
+	firstArgument   int
+	// function flag is set for the function endpoints, which bind path variables to the arguments and respond with
+	// 500 Internal Server Error to the errors.
+	function bool
+}
+
+// allMethods are the methods the routes without `web.methods` are documented with.
+var allMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// GenerateOpenAPI function generates OpenAPI document describing the routes of the server with the given name (empty
+// for the default server): paths and their parameters, request bodies and responses. JSON Schemas are derived from the
+// argument and result types of the handlers. Can be called once the container is initialized, e.g. from a CLI.
+func GenerateOpenAPI(serverName string, info openapi.Info) (*openapi.Document, error) {
+	routes, err := describeRoutes(serverName)
+	if err != nil {
+		return nil, err
+	}
+	document := &openapi.Document{OpenAPI: openapi.Version, Info: info, Paths: make(map[string]openapi.PathItem)}
+	schemas := &schemaGenerator{schemas: make(map[string]*openapi.Schema), names: make(map[reflect.Type]string)}
+	for _, route := range routes {
+		pathTemplate, parameters, err := pathParameters(route.path())
+		if err != nil {
+			return nil, err
+		}
+		methods := route.routeSpec.Methods
+		if len(methods) == 0 {
+			methods = allMethods
+		}
+		for _, method := range methods {
+			operation, err := describeOperation(route, parameters, schemas)
+			if err != nil {
+				return nil, err
+			}
+			operation.OperationID = operationID(route.name, method, pathTemplate, len(methods) > 1)
+			operation.Summary, operation.Description = route.routeSpec.Summary, route.routeSpec.Description
+			operation.Tags, operation.Deprecated = route.routeSpec.Tags, route.routeSpec.Deprecated
+			if document.Paths[pathTemplate] == nil {
+				document.Paths[pathTemplate] = make(openapi.PathItem)
+			}
+			document.Paths[pathTemplate][strings.ToLower(method)] = operation
+		}
+	}
+	if len(schemas.schemas) > 0 {
+		document.Components = &openapi.Components{Schemas: schemas.schemas}
+	}
+	return document, nil
+}
+
+func registerOpenAPIHandler(router *mux.Router, serverName string) error {
+	if _, ok := di.GetBeanTypes()[GoiocOpenAPI]; !ok {
+		return nil
+	}
+	instance, err := di.GetInstanceSafe(GoiocOpenAPI)
+	if err != nil {
+		return err
+	}
+	config, ok := instance.(*OpenAPIConfig)
+	if !ok {
+		return errors.New("bean is not an OpenAPI config: " + GoiocOpenAPI)
+	}
+	if config.Server != serverName {
+		return nil
+	}
+	document, err := GenerateOpenAPI(serverName, config.Info)
+	if err != nil {
+		return err
+	}
+	document.Servers = config.Servers
+	content, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	path := config.Path
+	if path == "" {
+		path = DefaultOpenAPIPath
+	}
+	router.Methods(http.MethodGet).Path(path).Name(GoiocOpenAPI).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(content); err != nil {
+			panic(err)
+		}
+	})
+	if config.Docs == nil || config.DocsEnabled != nil && !config.DocsEnabled() {
+		return nil
+	}
+	docsPath := strings.TrimSuffix(config.DocsPath, "/")
+	if docsPath == "" {
+		docsPath = DefaultDocsPath
+	}
+	router.Methods(http.MethodGet).Path(docsPath).Handler(http.RedirectHandler(docsPath+"/", http.StatusMovedPermanently))
+	router.Methods(http.MethodGet).PathPrefix(docsPath + "/").Name(GoiocOpenAPI + "Docs").
+		Handler(http.StripPrefix(docsPath, config.Docs(path)))
+	return nil
+}
+
+// describeRoutes function describes the routes of the server: endpoints and controllers in the order of bean IDs,
+// followed by function endpoints in the order of registration.
+func describeRoutes(serverName string) ([]routeDescription, error) {
+	endpointType := reflect.TypeOf((*Endpoint)(nil)).Elem()
+	controllerType := reflect.TypeOf((*Controller)(nil)).Elem()
+	beanTypes := di.GetBeanTypes()
+	var routes []routeDescription
+	for _, beanID := range sortedBeanIDs(beanTypes) {
+		beanType := beanTypes[beanID]
+		if beanType.Implements(endpointType) {
+			routeSpec, err := endpointRouteSpec(beanType.Elem())
+			if err != nil {
+				return nil, err
+			}
+			endpoint, _, err := beanResolver(beanID, beanType)
+			if err != nil {
+				return nil, err
+			}
+			routeSpec.HandlerFuncName = endpoint.(Endpoint).HandlerFuncName()
+			if routes, err = appendRoute(routes, serverName, beanID, beanID, routeSpec, beanType); err != nil {
+				return nil, err
+			}
+		}
+		if beanType.Implements(controllerType) {
+			controller, _, err := beanResolver(beanID, beanType)
+			if err != nil {
+				return nil, err
+			}
+			for _, routeSpec := range controller.(Controller).Routes() {
+				if routes, err = appendRoute(routes, serverName, beanID+"."+routeSpec.HandlerFuncName, beanID, routeSpec, beanType); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, functionEndpoint := range functionEndpoints {
+		routeSpec := functionEndpoint.routeSpec
+		if routeSpec.Server != serverName {
+			continue
+		}
+		route := routeDescription{
+			name:            routeSpec.HandlerFuncName,
+			routeSpec:       routeSpec,
+			handlerFuncType: reflect.TypeOf(functionEndpoint.handlerFunc),
+			function:        true,
+		}
+		if route.handlerFuncType == nil || route.handlerFuncType.Kind() != reflect.Func {
+			return nil, errors.New("handler is not a function: " + routeSpec.Path)
+		}
+		route.handler = functionName(functionEndpoint.handlerFunc)
+		if err := describeGroup(&route); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func appendRoute(routes []routeDescription, serverName string, name string, beanID string, routeSpec RouteSpec, beanType reflect.Type) ([]routeDescription, error) {
+	if routeSpec.Server != serverName {
+		return routes, nil
+	}
+	handlerFunc, ok := beanType.MethodByName(routeSpec.HandlerFuncName)
+	if !ok {
+		return nil, errors.New("handler method not found: " + routeSpec.HandlerFuncName)
+	}
+	route := routeDescription{
+		name:            name,
+		beanID:          beanID,
+		routeSpec:       routeSpec,
+		handler:         "(" + beanType.String() + ")." + routeSpec.HandlerFuncName,
+		handlerFuncType: handlerFunc.Type,
+		firstArgument:   1,
+	}
+	if err := describeGroup(&route); err != nil {
+		return nil, err
+	}
+	return append(routes, route), nil
+}
+
+func describeGroup(route *routeDescription) error {
+	if route.routeSpec.Group == "" {
+		return nil
+	}
+	instance, err := di.GetInstanceSafe(route.routeSpec.Group)
+	if err != nil {
+		return err
+	}
+	group, ok := instance.(*Group)
+	if !ok {
+		return errors.New("bean is not a group: " + route.routeSpec.Group)
+	}
+	route.group = group
+	return nil
+}
+
+// path method returns the path template of the route, prefixed with the path prefix of the group.
+func (rd routeDescription) path() string {
+	if rd.group == nil {
+		return rd.routeSpec.Path
+	}
+	return rd.group.Prefix + rd.routeSpec.Path
+}
+
+// headers method returns the key-value pairs of the headers required by the group and the route.
+func (rd routeDescription) headers() []string {
+	var headers []string
+	if rd.group != nil {
+		headers = append(headers, rd.group.Headers...)
+	}
+	return append(headers, rd.routeSpec.Headers...)
+}
+
+// describeOperation function describes path, query and header parameters, request body and responses of the route.
+func describeOperation(route routeDescription, pathParameters []*openapi.Parameter, schemas *schemaGenerator) (*openapi.Operation, error) {
+	operation := &openapi.Operation{Responses: make(map[string]*openapi.Response)}
+	queries := route.routeSpec.Queries
+	if len(queries)%2 != 0 {
+		return nil, errors.New("queries must be key-value pairs: " + strings.Join(queries, ","))
+	}
+	for i := 0; i < len(queries); i += 2 {
+		operation.Parameters = append(operation.Parameters, valueParameter(queries[i], "query", queries[i+1]))
+	}
+	contentType := ""
+	headers := route.headers()
+	if len(headers)%2 != 0 {
+		return nil, errors.New("headers must be key-value pairs: " + strings.Join(headers, ","))
+	}
+	for i := 0; i < len(headers); i += 2 {
+		switch http.CanonicalHeaderKey(headers[i]) {
+		case "Content-Type":
+			contentType = headers[i+1]
+		case "Accept", "Authorization":
+		default:
+			operation.Parameters = append(operation.Parameters, valueParameter(headers[i], "header", headers[i+1]))
+		}
+	}
+	injectedArguments, err := beanArguments(route.handlerFuncType, route.firstArgument, route.routeSpec.Qualifiers)
+	if err != nil {
+		return nil, err
+	}
+	var boundArguments map[int]string
+	if route.function {
+		pathVariables, err := pathVariableNames(route.path())
+		if err != nil {
+			return nil, err
+		}
+		boundArguments = pathArguments(route.handlerFuncType, route.firstArgument, pathVariables, injectedArguments)
+	}
+	operation.Parameters = append(typePathParameters(pathParameters, route.handlerFuncType, boundArguments, schemas),
+		operation.Parameters...)
+	for i := route.firstArgument; i < route.handlerFuncType.NumIn() && operation.RequestBody == nil; i++ {
+		if _, ok := injectedArguments[i]; ok {
+			continue
+		}
+		if _, ok := boundArguments[i]; ok {
+			continue
+		}
+		if mediaType, content := describeContent(route.handlerFuncType.In(i), schemas, true); content != nil {
+			if contentType != "" {
+				mediaType = contentType
+			}
+			operation.RequestBody = &openapi.RequestBody{Content: map[string]*openapi.MediaType{mediaType: content}}
+		}
+	}
+	results := route.handlerFuncType.NumOut()
+	if route.function && results > 0 && route.handlerFuncType.Out(results-1) == errorType {
+		operation.Responses[strconv.Itoa(http.StatusInternalServerError)] = &openapi.Response{
+			Description: http.StatusText(http.StatusInternalServerError),
+		}
+		results--
+	}
+	status, response := "200", &openapi.Response{Description: "OK"}
+	for i := 0; i < results; i++ {
+		result := route.handlerFuncType.Out(i)
+		if result == reflect.TypeOf(0) {
+			status, response.Description = "default", "Response"
+			continue
+		}
+		if mediaType, content := describeContent(result, schemas, false); content != nil {
+			response.Content = map[string]*openapi.MediaType{mediaType: content}
+			break
+		}
+	}
+	operation.Responses[status] = response
+	return operation, nil
+}
+
+// describeContent function returns the media type and the schema of the request body (or response) of the given type,
+// or nil if the type is not transferred in the body.
+func describeContent(t reflect.Type, schemas *schemaGenerator, request bool) (string, *openapi.MediaType) {
+	binary := &openapi.MediaType{Schema: &openapi.Schema{Type: "string", ContentMediaType: "application/octet-stream"}}
+	switch t {
+	case reflect.TypeOf((*string)(nil)).Elem():
+		return "text/plain", &openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	case reflect.TypeOf((*[]byte)(nil)).Elem(), reflect.TypeOf((*io.Reader)(nil)).Elem(),
+		reflect.TypeOf((*io.ReadCloser)(nil)).Elem():
+		return "application/octet-stream", binary
+	case reflect.TypeOf((*htmlTemplate.Template)(nil)).Elem(), reflect.TypeOf((*htmlTemplate.Template)(nil)),
+		reflect.TypeOf((*textTemplate.Template)(nil)).Elem(), reflect.TypeOf((*textTemplate.Template)(nil)),
+		reflect.TypeOf((*View)(nil)).Elem():
+		return "text/html", &openapi.MediaType{}
+	}
+	if builtinArgumentTypes[t] || t == reflect.TypeOf((*http.Header)(nil)).Elem() {
+		return "", nil
+	}
+	if request && reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()) ||
+		!request && t.Implements(reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()) {
+		return "application/octet-stream", binary
+	}
+	if request && reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) ||
+		!request && t.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) {
+		return "text/plain", &openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	}
+	return "application/json", &openapi.MediaType{Schema: schemas.schema(t)}
+}
+
+// pathParameters function converts mux path template to OpenAPI one (dropping variable patterns) and describes its
+// variables as path parameters.
+func pathParameters(path string) (string, []*openapi.Parameter, error) {
+	var template strings.Builder
+	var parameters []*openapi.Parameter
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			template.WriteByte(path[i])
+			continue
+		}
+		end, err := variableEnd(path, i)
+		if err != nil {
+			return "", nil, err
+		}
+		name, pattern, _ := strings.Cut(path[i+1:end], ":")
+		parameter := &openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+		if pattern != "" {
+			parameter.Schema.Pattern = "^" + pattern + "$"
+		}
+		parameters = append(parameters, parameter)
+		template.WriteString("{" + name + "}")
+		i = end
+	}
+	return template.String(), parameters, nil
+}
+
+// typePathParameters function returns the path parameters with the schemas derived from the types of the handler
+// arguments bound to them, e.g. "integer" for int. Variable patterns are kept for the arguments parsed from strings.
+func typePathParameters(parameters []*openapi.Parameter, handlerFuncType reflect.Type, boundArguments map[int]string, schemas *schemaGenerator) []*openapi.Parameter {
+	typed := append([]*openapi.Parameter(nil), parameters...)
+	for index, name := range boundArguments {
+		argument := handlerFuncType.In(index)
+		if argument.Kind() == reflect.String ||
+			reflect.PtrTo(argument).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+			continue
+		}
+		for i, parameter := range typed {
+			if parameter.Name == name {
+				typed[i] = &openapi.Parameter{Name: name, In: parameter.In, Required: true, Schema: schemas.schema(argument)}
+			}
+		}
+	}
+	return typed
+}
+
+// variableEnd function returns the index of the brace closing the variable started at the given index.
+func variableEnd(template string, start int) (int, error) {
+	level := 0
+	for i := start; i < len(template); i++ {
+		switch template[i] {
+		case '{':
+			level++
+		case '}':
+			if level--; level == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, errors.New("unbalanced braces in " + template)
+}
+
+// valueParameter function describes required query or header parameter with the value from mux template: a variable
+// (optionally with a pattern), a literal value or any value, if empty.
+func valueParameter(name string, in string, value string) *openapi.Parameter {
+	parameter := &openapi.Parameter{Name: name, In: in, Required: true, Schema: &openapi.Schema{Type: "string"}}
+	if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		if _, pattern, ok := strings.Cut(value[1:len(value)-1], ":"); ok {
+			parameter.Schema.Pattern = "^" + pattern + "$"
+		}
+	} else if value != "" {
+		parameter.Schema.Enum = []interface{}{value}
+	}
+	return parameter
+}
+
+var nonIdentifierCharacters = regexp.MustCompile(`[^A-Za-z0-9_.]+`)
+
+// operationID function returns ID of the operation: the route name (suffixed with the method, if the route serves
+// several ones), or the method and the path for the unnamed routes.
+func operationID(name string, method string, pathTemplate string, severalMethods bool) string {
+	if name == "" {
+		return strings.ToLower(method) + strings.TrimRight(nonIdentifierCharacters.ReplaceAllString(pathTemplate, "_"), "_")
+	}
+	if severalMethods {
+		return name + "_" + strings.ToLower(method)
+	}
+	return name
+}
+
+// exampleValue function parses the example from `web.example` tag: as is for strings, as JSON for other types (falling
+// back to the string if it's not a valid JSON).
+func exampleValue(value string, schema *openapi.Schema) interface{} {
+	if schema.Type == "string" {
+		return value
+	}
+	var example interface{}
+	if err := json.Unmarshal([]byte(value), &example); err != nil {
+		return value
+	}
+	return example
+}
+
+// schemaGenerator derives JSON Schemas from Go types, putting named structures to the components.
+type schemaGenerator struct {
+	schemas map[string]*openapi.Schema
+	names   map[reflect.Type]string
+}
+
+func (sg *schemaGenerator) schema(t reflect.Type) *openapi.Schema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &openapi.Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) ||
+		reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+		return &openapi.Schema{}
+	}
+	if t.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) ||
+		reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) {
+		return &openapi.Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openapi.Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &openapi.Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &openapi.Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		minimum := 0.0
+		return &openapi.Schema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32:
+		return &openapi.Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openapi.Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openapi.Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &openapi.Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &openapi.Schema{Type: "array", Items: sg.schema(t.Elem())}
+	case reflect.Map:
+		return &openapi.Schema{Type: "object", AdditionalProperties: sg.schema(t.Elem())}
+	case reflect.Ptr:
+		return sg.schema(t.Elem())
+	case reflect.Struct:
+		if t.Name() == "" {
+			schema := &openapi.Schema{Type: "object"}
+			sg.properties(t, schema)
+			return schema
+		}
+		if name, ok := sg.names[t]; ok {
+			return openapi.SchemaRef(name)
+		}
+		name := sg.name(t)
+		schema := &openapi.Schema{Type: "object"}
+		sg.names[t], sg.schemas[name] = name, schema
+		sg.properties(t, schema)
+		return openapi.SchemaRef(name)
+	}
+	return &openapi.Schema{}
+}
+
+// name method returns unique component name of the named type.
+func (sg *schemaGenerator) name(t reflect.Type) string {
+	base := nonIdentifierCharacters.ReplaceAllString(t.Name(), "_")
+	name := base
+	for i := 2; sg.schemas[name] != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	return name
+}
+
+// properties method describes the fields of the structure as encoding/json serializes them, flattening the embedded
+// structures. Descriptions and examples of the fields are taken from `web.doc` and `web.example` tags.
+func (sg *schemaGenerator) properties(t reflect.Type, schema *openapi.Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				sg.properties(fieldType, schema)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if schema.Properties == nil {
+			schema.Properties = make(map[string]*openapi.Schema)
+		}
+		property := sg.schema(fieldType)
+		property.Description = field.Tag.Get(doc)
+		if value, ok := field.Tag.Lookup(example); ok {
+			property.Examples = []interface{}{exampleValue(value, property)}
+		}
+		schema.Properties[name] = property
+		if !strings.Contains(","+options+",", ",omitempty,") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+// Load function loads the document from the JSON or YAML file.
+func Load(file string) (*Document, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse function parses the document in JSON or YAML format.
+func Parse(data []byte) (*Document, error) {
+	var content interface{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(stringKeys(content))
+	if err != nil {
+		return nil, err
+	}
+	var document Document
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// stringKeys function converts non-string keys of YAML mappings (e.g. unquoted status codes) to strings, so that the
+// content can be marshalled to JSON.
+func stringKeys(content interface{}) interface{} {
+	switch content := content.(type) {
+	case map[string]interface{}:
+		for key, value := range content {
+			content[key] = stringKeys(value)
+		}
+	case map[interface{}]interface{}:
+		mapping := make(map[string]interface{}, len(content))
+		for key, value := range content {
+			mapping[fmt.Sprint(key)] = stringKeys(value)
+		}
+		return mapping
+	case []interface{}:
+		for i, value := range content {
+			content[i] = stringKeys(value)
+		}
+	}
+	return content
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package openapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	document, err := Load("testdata/users.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "Users", document.Info.Title)
+	assert.Equal(t, "https://example.org/api", document.Servers[0].URL)
+	assert.Len(t, document.Paths, 3)
+	assert.Contains(t, document.Paths["/users"]["get"].Responses, "200")
+	assert.Equal(t, "id", document.Paths["/users/{id}"]["get"].Parameters[0].Name)
+	assert.Equal(t, "#/components/parameters/RequestID", document.Paths["/users"]["post"].Parameters[0].Ref)
+	user := document.Components.Schemas["User"]
+	assert.True(t, user.Properties["age"].Nullable)
+	assert.Equal(t, "integer", user.Properties["age"].Type)
+	assert.NotNil(t, user.AdditionalProperties.Not)
+}
+
+func TestParseJSON(t *testing.T) {
+	document, err := Parse([]byte(`{"openapi":"3.1.0","info":{"title":"Test","version":"1"},"paths":{"/":{"get":{"responses":{"204":{"description":"No content"}}}}},"components":{"schemas":{"Name":{"type":["string","null"]}}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "3.1.0", document.OpenAPI)
+	assert.Contains(t, document.Paths["/"]["get"].Responses, "204")
+	assert.Equal(t, "string", document.Components.Schemas["Name"].Type)
+	assert.True(t, document.Components.Schemas["Name"].Nullable)
+}
+
+func TestParseExclusiveBounds(t *testing.T) {
+	document, err := Parse([]byte(`
+openapi: 3.0.3
+info: {title: Test, version: "1"}
+paths: {}
+components:
+  schemas:
+    Legacy: {type: number, minimum: 0, exclusiveMinimum: true, maximum: 10, exclusiveMaximum: false}
+    Modern: {type: number, exclusiveMinimum: 0, maximum: 10}
+`))
+	assert.NoError(t, err)
+	legacy := document.Components.Schemas["Legacy"]
+	assert.Nil(t, legacy.Minimum)
+	assert.Equal(t, 0.0, *legacy.ExclusiveMinimum)
+	assert.Equal(t, 10.0, *legacy.Maximum)
+	assert.Nil(t, legacy.ExclusiveMaximum)
+	modern := document.Components.Schemas["Modern"]
+	assert.Nil(t, modern.Minimum)
+	assert.Equal(t, 0.0, *modern.ExclusiveMinimum)
+	assert.Equal(t, 10.0, *modern.Maximum)
+	_, err = Parse([]byte(`{"openapi":"3.0.3","components":{"schemas":{"Bad":{"exclusiveMinimum":"0"}}}}`))
+	assert.Error(t, err)
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte("openapi: ["))
+	assert.Error(t, err)
+	_, err = Load("testdata/missing.yaml")
+	assert.Error(t, err)
+}
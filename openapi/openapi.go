@@ -0,0 +1,306 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Package openapi contains the model of OpenAPI 3.1 document, as far as it's generated and validated by goioc/web.
+package openapi
+
+import "encoding/json"
+
+// Version is a version of OpenAPI specification the documents conform to.
+const Version = "3.1.0"
+
+// Document is a root object of OpenAPI document.
+type Document struct {
+	// OpenAPI is a version of the specification, see Version.
+	OpenAPI string `json:"openapi"`
+	// Info is a metadata of the API.
+	Info Info `json:"info"`
+	// Servers are the servers the API is available at.
+	Servers []Server `json:"servers,omitempty"`
+	// Paths maps path templates (e.g. "/users/{id}") to the operations.
+	Paths map[string]PathItem `json:"paths"`
+	// Components holds reusable schemas.
+	Components *Components `json:"components,omitempty"`
+}
+
+// Info is a metadata of the API.
+type Info struct {
+	// Title is a title of the API.
+	Title string `json:"title"`
+	// Version is a version of the API (not of the specification).
+	Version string `json:"version"`
+	// Description is a description of the API.
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a server the API is available at.
+type Server struct {
+	// URL is a URL of the server.
+	URL string `json:"url"`
+	// Description is a description of the server.
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP-methods to the operations available at the path. When unmarshalled, other fields of
+// the path item are ignored, except for the parameters, which are merged into the operations.
+type PathItem map[string]*Operation
+
+// UnmarshalJSON method unmarshals the path item, merging path-level parameters into the operations.
+func (pi *PathItem) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var parameters []*Parameter
+	if value, ok := fields["parameters"]; ok {
+		if err := json.Unmarshal(value, &parameters); err != nil {
+			return err
+		}
+	}
+	*pi = make(PathItem)
+	for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+		value, ok := fields[method]
+		if !ok {
+			continue
+		}
+		var operation Operation
+		if err := json.Unmarshal(value, &operation); err != nil {
+			return err
+		}
+	L:
+		for _, parameter := range parameters {
+			for _, operationParameter := range operation.Parameters {
+				if operationParameter.Name == parameter.Name && operationParameter.In == parameter.In &&
+					operationParameter.Ref == parameter.Ref {
+					continue L
+				}
+			}
+			operation.Parameters = append(operation.Parameters, parameter)
+		}
+		(*pi)[method] = &operation
+	}
+	return nil
+}
+
+// Operation is a single API operation on a path.
+type Operation struct {
+	// OperationID is a unique identifier of the operation.
+	OperationID string `json:"operationId,omitempty"`
+	// Summary is a short summary of the operation.
+	Summary string `json:"summary,omitempty"`
+	// Description is a verbose description of the operation.
+	Description string `json:"description,omitempty"`
+	// Tags is a list of tags grouping the operations.
+	Tags []string `json:"tags,omitempty"`
+	// Deprecated flag marks the operation as deprecated.
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Parameters are path, query and header parameters of the operation.
+	Parameters []*Parameter `json:"parameters,omitempty"`
+	// RequestBody is a request body of the operation.
+	RequestBody *RequestBody `json:"requestBody,omitempty"`
+	// Responses maps status codes (or "default") to the responses.
+	Responses map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter of the operation.
+type Parameter struct {
+	// Ref is a reference to the parameter defined in Components.
+	Ref string `json:"$ref,omitempty"`
+	// Name is a name of the parameter.
+	Name string `json:"name,omitempty"`
+	// In is a location of the parameter: "path", "query" or "header".
+	In string `json:"in,omitempty"`
+	// Required flag marks mandatory parameters. Path parameters are always required.
+	Required bool `json:"required,omitempty"`
+	// Schema is a schema of the parameter value.
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody is a request body of the operation.
+type RequestBody struct {
+	// Required flag marks mandatory request bodies.
+	Required bool `json:"required,omitempty"`
+	// Content maps media types to their schemas.
+	Content map[string]*MediaType `json:"content"`
+}
+
+// MediaType is a schema of the content of a particular media type.
+type MediaType struct {
+	// Schema is a schema of the content.
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Response is a response of the operation.
+type Response struct {
+	// Description is a description of the response.
+	Description string `json:"description"`
+	// Content maps media types to their schemas.
+	Content map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Components holds reusable objects of the document.
+type Components struct {
+	// Schemas maps names to the schemas, referred to as "#/components/schemas/<name>".
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+	// Parameters maps names to the parameters, referred to as "#/components/parameters/<name>".
+	Parameters map[string]*Parameter `json:"parameters,omitempty"`
+}
+
+// Schema is a JSON Schema (draft 2020-12) of a value.
+type Schema struct {
+	// Ref is a reference to the schema defined in Components.
+	Ref string `json:"$ref,omitempty"`
+	// Type is a JSON type of the value: "object", "array", "string", "number", "integer" or "boolean".
+	Type string `json:"-"`
+	// Nullable flag allows null value besides the values of Type (i.e. `"type": [Type, "null"]`).
+	Nullable bool `json:"-"`
+	// Format refines the type, e.g. "int64" or "date-time".
+	Format string `json:"format,omitempty"`
+	// Description is a description of the value.
+	Description string `json:"description,omitempty"`
+	// Examples is a list of example values.
+	Examples []interface{} `json:"examples,omitempty"`
+	// Pattern is a regular expression the string value must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Enum is a list of allowed values.
+	Enum []interface{} `json:"enum,omitempty"`
+	// Minimum is a minimal numeric value.
+	Minimum *float64 `json:"minimum,omitempty"`
+	// Maximum is a maximal numeric value.
+	Maximum *float64 `json:"maximum,omitempty"`
+	// ExclusiveMinimum is a numeric value the value must be greater than.
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	// ExclusiveMaximum is a numeric value the value must be less than.
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	// MinLength is a minimal length of the string value.
+	MinLength *int `json:"minLength,omitempty"`
+	// MaxLength is a maximal length of the string value.
+	MaxLength *int `json:"maxLength,omitempty"`
+	// Items is a schema of the array items.
+	Items *Schema `json:"items,omitempty"`
+	// MinItems is a minimal length of the array.
+	MinItems *int `json:"minItems,omitempty"`
+	// MaxItems is a maximal length of the array.
+	MaxItems *int `json:"maxItems,omitempty"`
+	// Properties maps object property names to their schemas.
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// Required is a list of mandatory object properties.
+	Required []string `json:"required,omitempty"`
+	// AdditionalProperties is a schema of the object properties not listed in Properties.
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+	// ContentEncoding is an encoding of the string value, e.g. "base64".
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+	// ContentMediaType is a media type of the string value, e.g. "application/octet-stream".
+	ContentMediaType string `json:"contentMediaType,omitempty"`
+	// AllOf is a list of schemas the value must match all of.
+	AllOf []*Schema `json:"allOf,omitempty"`
+	// AnyOf is a list of schemas the value must match at least one of.
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	// OneOf is a list of schemas the value must match exactly one of.
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	// Not is a schema the value must not match.
+	Not *Schema `json:"not,omitempty"`
+}
+
+// schema is an alias of Schema without custom (un)marshalling.
+type schema Schema
+
+// MarshalJSON method marshals the schema, representing nullable type as an array.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	var schemaType interface{}
+	if s.Type != "" && s.Nullable {
+		schemaType = []string{s.Type, "null"}
+	} else if s.Type != "" {
+		schemaType = s.Type
+	}
+	return json.Marshal(struct {
+		Type interface{} `json:"type,omitempty"`
+		*schema
+	}{Type: schemaType, schema: (*schema)(s)})
+}
+
+// UnmarshalJSON method unmarshals the schema, accepting the type as a string or as an array (with "null" for nullable
+// values), OpenAPI 3.0 "nullable" keyword, OpenAPI 3.0 boolean "exclusiveMinimum" and "exclusiveMaximum" (which make
+// "minimum" and "maximum" exclusive) and boolean schemas (true allows any value, false - none).
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		if !allowed {
+			s.Not = &Schema{}
+		}
+		return nil
+	}
+	var fields struct {
+		Type             json.RawMessage `json:"type"`
+		Nullable         bool            `json:"nullable"`
+		ExclusiveMinimum json.RawMessage `json:"exclusiveMinimum"`
+		ExclusiveMaximum json.RawMessage `json:"exclusiveMaximum"`
+		*schema
+	}
+	fields.schema = (*schema)(s)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	s.Nullable = fields.Nullable
+	var err error
+	if s.ExclusiveMinimum, s.Minimum, err = exclusiveBound(fields.ExclusiveMinimum, s.Minimum); err != nil {
+		return err
+	}
+	if s.ExclusiveMaximum, s.Maximum, err = exclusiveBound(fields.ExclusiveMaximum, s.Maximum); err != nil {
+		return err
+	}
+	if len(fields.Type) == 0 {
+		return nil
+	}
+	var types []string
+	if err := json.Unmarshal(fields.Type, &s.Type); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(fields.Type, &types); err != nil {
+		return err
+	}
+	for _, schemaType := range types {
+		if schemaType == "null" {
+			s.Nullable = true
+		} else {
+			s.Type = schemaType
+		}
+	}
+	return nil
+}
+
+// exclusiveBound function returns the exclusive and the inclusive bounds given the exclusive bound keyword, which is a
+// number in OpenAPI 3.1 and a boolean modifying the inclusive bound in OpenAPI 3.0.
+func exclusiveBound(keyword json.RawMessage, inclusive *float64) (*float64, *float64, error) {
+	if len(keyword) == 0 {
+		return nil, inclusive, nil
+	}
+	var exclusive bool
+	if err := json.Unmarshal(keyword, &exclusive); err == nil {
+		if exclusive {
+			return inclusive, nil, nil
+		}
+		return nil, inclusive, nil
+	}
+	var bound float64
+	if err := json.Unmarshal(keyword, &bound); err != nil {
+		return nil, nil, err
+	}
+	return &bound, inclusive, nil
+}
+
+// SchemaRef function returns the schema referring to the component schema with the given name.
+func SchemaRef(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
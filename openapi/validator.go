@@ -0,0 +1,597 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidationError is a violation of the document by the request or the response.
+type ValidationError struct {
+	// In is a location of the violation: "path", "query", "header", "cookie", "body" or "status".
+	In string `json:"in"`
+	// Name is a name of the parameter or JSON pointer to the invalid part of the body, e.g. "/items/0/name".
+	Name string `json:"name,omitempty"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// ValidationErrors is a list of violations, returned by Validator as an error.
+type ValidationErrors []ValidationError
+
+// Error method lists the violations.
+func (ve ValidationErrors) Error() string {
+	messages := make([]string, 0, len(ve))
+	for _, validationError := range ve {
+		messages = append(messages, strings.TrimSpace(validationError.In+" "+validationError.Name)+": "+validationError.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator validates requests and responses against the document.
+type Validator struct {
+	document *Document
+	basePath string
+	paths    []validatorPath
+	patterns sync.Map
+}
+
+type validatorPath struct {
+	pattern    *regexp.Regexp
+	names      []string
+	variables  int
+	pathItem   PathItem
+	pathLength int
+}
+
+// NewValidator function creates Validator for the document. If the first server of the document has URL with a path
+// (e.g. "https://example.org/api/v1"), request paths are matched relative to it.
+func NewValidator(document *Document) (*Validator, error) {
+	validator := &Validator{document: document}
+	if len(document.Servers) > 0 {
+		if serverURL, err := url.Parse(document.Servers[0].URL); err == nil {
+			validator.basePath = strings.TrimSuffix(serverURL.Path, "/")
+		}
+	}
+	for path, pathItem := range document.Paths {
+		var pattern strings.Builder
+		var names []string
+		pattern.WriteString("^")
+		for rest := path; rest != ""; {
+			start := strings.Index(rest, "{")
+			end := strings.Index(rest, "}")
+			if start < 0 || end < start {
+				pattern.WriteString(regexp.QuoteMeta(rest))
+				break
+			}
+			pattern.WriteString(regexp.QuoteMeta(rest[:start]) + "([^/]+)")
+			names = append(names, rest[start+1:end])
+			rest = rest[end+1:]
+		}
+		pattern.WriteString("$")
+		compiled, err := regexp.Compile(pattern.String())
+		if err != nil {
+			return nil, err
+		}
+		validator.paths = append(validator.paths, validatorPath{
+			pattern:    compiled,
+			names:      names,
+			variables:  len(names),
+			pathItem:   pathItem,
+			pathLength: len(path),
+		})
+	}
+	// concrete paths take precedence over the templated ones
+	sort.Slice(validator.paths, func(i, j int) bool {
+		if validator.paths[i].variables != validator.paths[j].variables {
+			return validator.paths[i].variables < validator.paths[j].variables
+		}
+		return validator.paths[i].pathLength > validator.paths[j].pathLength
+	})
+	return validator, nil
+}
+
+// FindOperation method returns the operation describing the request together with the values of the path parameters,
+// or nil if the document doesn't describe the request.
+func (v *Validator) FindOperation(r *http.Request) (*Operation, map[string]string) {
+	path, ok := v.relativePath(r)
+	if !ok {
+		return nil, nil
+	}
+	for _, validatorPath := range v.paths {
+		matches := validatorPath.pattern.FindStringSubmatch(path)
+		if matches == nil {
+			continue
+		}
+		operation, ok := validatorPath.pathItem[strings.ToLower(r.Method)]
+		if !ok {
+			continue
+		}
+		parameters := make(map[string]string, len(validatorPath.names))
+		for i, name := range validatorPath.names {
+			parameters[name] = matches[i+1]
+		}
+		return operation, parameters
+	}
+	return nil, nil
+}
+
+// AllowedMethods method returns the sorted methods of the operations described by the document for the path of the
+// request, or nil if the document doesn't describe the path at all.
+func (v *Validator) AllowedMethods(r *http.Request) []string {
+	path, ok := v.relativePath(r)
+	if !ok {
+		return nil
+	}
+	allowed := make(map[string]bool)
+	for _, validatorPath := range v.paths {
+		if validatorPath.pattern.MatchString(path) {
+			for method := range validatorPath.pathItem {
+				allowed[strings.ToUpper(method)] = true
+			}
+		}
+	}
+	var methods []string
+	for method := range allowed {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+func (v *Validator) relativePath(r *http.Request) (string, bool) {
+	if !strings.HasPrefix(r.URL.Path, v.basePath) {
+		return "", false
+	}
+	return strings.TrimPrefix(r.URL.Path, v.basePath), true
+}
+
+// ValidateRequest method validates the parameters and the body of the request against the operation, returning
+// ValidationErrors if the request is invalid. The body is replaced with a copy, so that it can be read again.
+func (v *Validator) ValidateRequest(r *http.Request, operation *Operation, pathParameters map[string]string) error {
+	var validationErrors ValidationErrors
+	for _, parameter := range operation.Parameters {
+		parameter, err := v.resolveParameter(parameter)
+		if err != nil {
+			return err
+		}
+		var values []string
+		switch parameter.In {
+		case "path":
+			if value, ok := pathParameters[parameter.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = r.URL.Query()[parameter.Name]
+		case "header":
+			values = r.Header.Values(parameter.Name)
+		case "cookie":
+			if cookie, err := r.Cookie(parameter.Name); err == nil {
+				values = []string{cookie.Value}
+			}
+		}
+		if len(values) == 0 {
+			if parameter.Required || parameter.In == "path" {
+				validationErrors = append(validationErrors, ValidationError{In: parameter.In, Name: parameter.Name, Message: "is required"})
+			}
+			continue
+		}
+		if err := v.validateParameter(parameter, values, &validationErrors); err != nil {
+			return err
+		}
+	}
+	if operation.RequestBody != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if len(body) == 0 {
+			if operation.RequestBody.Required {
+				validationErrors = append(validationErrors, ValidationError{In: "body", Message: "is required"})
+			}
+		} else if err := v.validateContent(operation.RequestBody.Content, r.Header.Get("Content-Type"), body, "body", &validationErrors); err != nil {
+			return err
+		}
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+// ValidateResponse method validates the status code and the body of the response against the operation, returning
+// ValidationErrors if the response is invalid.
+func (v *Validator) ValidateResponse(operation *Operation, statusCode int, header http.Header, body []byte) error {
+	status := strconv.Itoa(statusCode)
+	response, ok := operation.Responses[status]
+	if !ok {
+		response, ok = operation.Responses[status[:1]+"XX"]
+	}
+	if !ok {
+		response, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return ValidationErrors{{In: "status", Name: status, Message: "is not described by the operation"}}
+	}
+	var validationErrors ValidationErrors
+	if len(response.Content) > 0 && len(body) > 0 {
+		if err := v.validateContent(response.Content, header.Get("Content-Type"), body, "body", &validationErrors); err != nil {
+			return err
+		}
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+func (v *Validator) validateParameter(parameter *Parameter, values []string, validationErrors *ValidationErrors) error {
+	schema, err := v.resolveSchema(parameter.Schema)
+	if err != nil || schema == nil {
+		return err
+	}
+	if schema.Type == "array" {
+		items, err := v.resolveSchema(schema.Items)
+		if err != nil {
+			return err
+		}
+		array := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				converted, ok := convertParameter(items, item)
+				if !ok {
+					*validationErrors = append(*validationErrors, ValidationError{In: parameter.In, Name: parameter.Name, Message: "must be an array of " + items.Type})
+					return nil
+				}
+				array = append(array, converted)
+			}
+		}
+		return v.validateValue(schema, array, parameter.In, parameter.Name, validationErrors)
+	}
+	value, ok := convertParameter(schema, values[0])
+	if !ok {
+		*validationErrors = append(*validationErrors, ValidationError{In: parameter.In, Name: parameter.Name, Message: "must be " + article(schema.Type)})
+		return nil
+	}
+	return v.validateValue(schema, value, parameter.In, parameter.Name, validationErrors)
+}
+
+// convertParameter function converts the parameter value to the JSON type of the schema.
+func convertParameter(schema *Schema, value string) (interface{}, bool) {
+	if schema == nil {
+		return value, true
+	}
+	switch schema.Type {
+	case "integer", "number":
+		number, err := strconv.ParseFloat(value, 64)
+		return number, err == nil
+	case "boolean":
+		boolean, err := strconv.ParseBool(value)
+		return boolean, err == nil
+	}
+	return value, true
+}
+
+func (v *Validator) validateContent(content map[string]*MediaType, contentType string, body []byte, in string, validationErrors *ValidationErrors) error {
+	if contentType == "" {
+		*validationErrors = append(*validationErrors, ValidationError{In: "header", Name: "Content-Type", Message: "is required"})
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	media, ok := content[mediaType]
+	if !ok {
+		media, ok = content[strings.Split(mediaType, "/")[0]+"/*"]
+	}
+	if !ok {
+		media, ok = content["*/*"]
+	}
+	if !ok {
+		*validationErrors = append(*validationErrors, ValidationError{In: "header", Name: "Content-Type", Message: "media type is not supported: " + contentType})
+		return nil
+	}
+	if media == nil || media.Schema == nil || mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		*validationErrors = append(*validationErrors, ValidationError{In: in, Message: "invalid JSON: " + err.Error()})
+		return nil
+	}
+	return v.validateValue(media.Schema, value, in, "", validationErrors)
+}
+
+// validateValue method validates the value decoded from JSON (or converted parameter) against the schema.
+func (v *Validator) validateValue(schema *Schema, value interface{}, in string, name string, validationErrors *ValidationErrors) error {
+	schema, err := v.resolveSchema(schema)
+	if err != nil || schema == nil {
+		return err
+	}
+	fail := func(message string) {
+		*validationErrors = append(*validationErrors, ValidationError{In: in, Name: name, Message: message})
+	}
+	if value == nil {
+		if schema.Type != "" && !schema.Nullable {
+			fail("must not be null")
+		}
+		return nil
+	}
+	if !hasType(value, schema.Type) {
+		fail("must be " + article(schema.Type))
+		return nil
+	}
+	if len(schema.Enum) > 0 && !contains(schema.Enum, value) {
+		fail(fmt.Sprintf("must be one of %v", schema.Enum))
+	}
+	switch value := value.(type) {
+	case string:
+		if err := v.validateString(schema, value, fail); err != nil {
+			return err
+		}
+	case float64:
+		validateNumber(schema, value, fail)
+	case []interface{}:
+		if schema.MinItems != nil && len(value) < *schema.MinItems {
+			fail("must have at least " + strconv.Itoa(*schema.MinItems) + " items")
+		}
+		if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+			fail("must have at most " + strconv.Itoa(*schema.MaxItems) + " items")
+		}
+		for i, item := range value {
+			if err := v.validateValue(schema.Items, item, in, name+"/"+strconv.Itoa(i), validationErrors); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for _, property := range schema.Required {
+			if _, ok := value[property]; !ok {
+				*validationErrors = append(*validationErrors, ValidationError{In: in, Name: name + "/" + escapePointer(property), Message: "is required"})
+			}
+		}
+		properties := make([]string, 0, len(value))
+		for property := range value {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+		for _, property := range properties {
+			propertySchema, ok := schema.Properties[property]
+			if !ok {
+				propertySchema = schema.AdditionalProperties
+			}
+			if err := v.validateValue(propertySchema, value[property], in, name+"/"+escapePointer(property), validationErrors); err != nil {
+				return err
+			}
+		}
+	}
+	return v.validateComposition(schema, value, in, name, validationErrors)
+}
+
+func (v *Validator) validateString(schema *Schema, value string, fail func(string)) error {
+	length := utf8.RuneCountInString(value)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		fail("must be at least " + strconv.Itoa(*schema.MinLength) + " characters long")
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		fail("must be at most " + strconv.Itoa(*schema.MaxLength) + " characters long")
+	}
+	if schema.Pattern != "" {
+		pattern, err := v.pattern(schema.Pattern)
+		if err != nil {
+			return err
+		}
+		if !pattern.MatchString(value) {
+			fail("must match pattern " + schema.Pattern)
+		}
+	}
+	var err error
+	switch schema.Format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "date":
+		_, err = time.Parse("2006-01-02", value)
+	case "email":
+		_, err = mail.ParseAddress(value)
+	case "uuid":
+		if !uuidPattern.MatchString(value) {
+			err = errors.New("invalid UUID")
+		}
+	}
+	if err != nil {
+		fail("must be a valid " + schema.Format)
+	}
+	return nil
+}
+
+func validateNumber(schema *Schema, value float64, fail func(string)) {
+	if schema.Minimum != nil && value < *schema.Minimum {
+		fail(fmt.Sprintf("must be greater than or equal to %v", *schema.Minimum))
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		fail(fmt.Sprintf("must be less than or equal to %v", *schema.Maximum))
+	}
+	if schema.ExclusiveMinimum != nil && value <= *schema.ExclusiveMinimum {
+		fail(fmt.Sprintf("must be greater than %v", *schema.ExclusiveMinimum))
+	}
+	if schema.ExclusiveMaximum != nil && value >= *schema.ExclusiveMaximum {
+		fail(fmt.Sprintf("must be less than %v", *schema.ExclusiveMaximum))
+	}
+}
+
+func (v *Validator) validateComposition(schema *Schema, value interface{}, in string, name string, validationErrors *ValidationErrors) error {
+	for _, allOf := range schema.AllOf {
+		if err := v.validateValue(allOf, value, in, name, validationErrors); err != nil {
+			return err
+		}
+	}
+	matches := func(schemas []*Schema) (int, error) {
+		count := 0
+		for _, candidate := range schemas {
+			var candidateErrors ValidationErrors
+			if err := v.validateValue(candidate, value, in, name, &candidateErrors); err != nil {
+				return 0, err
+			}
+			if len(candidateErrors) == 0 {
+				count++
+			}
+		}
+		return count, nil
+	}
+	if len(schema.AnyOf) > 0 {
+		count, err := matches(schema.AnyOf)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			*validationErrors = append(*validationErrors, ValidationError{In: in, Name: name, Message: "must match at least one of the schemas"})
+		}
+	}
+	if len(schema.OneOf) > 0 {
+		count, err := matches(schema.OneOf)
+		if err != nil {
+			return err
+		}
+		if count != 1 {
+			*validationErrors = append(*validationErrors, ValidationError{In: in, Name: name, Message: "must match exactly one of the schemas"})
+		}
+	}
+	if schema.Not != nil {
+		count, err := matches([]*Schema{schema.Not})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			*validationErrors = append(*validationErrors, ValidationError{In: in, Name: name, Message: "is not allowed"})
+		}
+	}
+	return nil
+}
+
+func (v *Validator) resolveSchema(schema *Schema) (*Schema, error) {
+	for i := 0; schema != nil && schema.Ref != ""; i++ {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		if name == schema.Ref || i > 32 {
+			return nil, errors.New("unsupported schema reference: " + schema.Ref)
+		}
+		var ok bool
+		if v.document.Components != nil {
+			schema, ok = v.document.Components.Schemas[name]
+		}
+		if !ok {
+			return nil, errors.New("schema is not found: " + name)
+		}
+	}
+	return schema, nil
+}
+
+func (v *Validator) resolveParameter(parameter *Parameter) (*Parameter, error) {
+	if parameter.Ref == "" {
+		return parameter, nil
+	}
+	name := strings.TrimPrefix(parameter.Ref, "#/components/parameters/")
+	if name == parameter.Ref {
+		return nil, errors.New("unsupported parameter reference: " + parameter.Ref)
+	}
+	if v.document.Components != nil {
+		if resolved, ok := v.document.Components.Parameters[name]; ok {
+			return resolved, nil
+		}
+	}
+	return nil, errors.New("parameter is not found: " + name)
+}
+
+func (v *Validator) pattern(pattern string) (*regexp.Regexp, error) {
+	if compiled, ok := v.patterns.Load(pattern); ok {
+		return compiled.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns.Store(pattern, compiled)
+	return compiled, nil
+}
+
+func hasType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	}
+	return true
+}
+
+func contains(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+		if number, ok := value.(float64); ok && fmt.Sprint(candidate) == strconv.FormatFloat(number, 'f', -1, 64) {
+			return true
+		}
+	}
+	return false
+}
+
+func article(schemaType string) string {
+	switch schemaType {
+	case "object", "array", "integer":
+		return "an " + schemaType
+	case "":
+		return "a value"
+	}
+	return "a " + schemaType
+}
+
+// escapePointer function escapes the reference token of JSON pointer (RFC 6901).
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package openapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestValidator(t *testing.T) *Validator {
+	document, err := Load("testdata/users.yaml")
+	assert.NoError(t, err)
+	validator, err := NewValidator(document)
+	assert.NoError(t, err)
+	return validator
+}
+
+func validateRequest(t *testing.T, validator *Validator, r *http.Request) error {
+	operation, pathParameters := validator.FindOperation(r)
+	if !assert.NotNil(t, operation, r.URL.Path) {
+		return nil
+	}
+	return validator.ValidateRequest(r, operation, pathParameters)
+}
+
+func TestFindOperation(t *testing.T) {
+	validator := newTestValidator(t)
+	operation, pathParameters := validator.FindOperation(httptest.NewRequest(http.MethodGet, "/api/users/me", nil))
+	assert.Contains(t, operation.Responses, "200")
+	assert.Empty(t, pathParameters)
+	operation, pathParameters = validator.FindOperation(httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+	assert.Contains(t, operation.Responses, "2XX")
+	assert.Equal(t, map[string]string{"id": "42"}, pathParameters)
+	operation, _ = validator.FindOperation(httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Nil(t, operation)
+	operation, _ = validator.FindOperation(httptest.NewRequest(http.MethodDelete, "/api/users/42", nil))
+	assert.Nil(t, operation)
+}
+
+func TestAllowedMethods(t *testing.T) {
+	validator := newTestValidator(t)
+	assert.Equal(t, []string{"GET", "POST"}, validator.AllowedMethods(httptest.NewRequest(http.MethodDelete, "/api/users", nil)))
+	assert.Nil(t, validator.AllowedMethods(httptest.NewRequest(http.MethodGet, "/api/unknown", nil)))
+	assert.Nil(t, validator.AllowedMethods(httptest.NewRequest(http.MethodGet, "/users", nil)))
+}
+
+func TestValidateParameters(t *testing.T) {
+	validator := newTestValidator(t)
+	assert.NoError(t, validateRequest(t, validator, httptest.NewRequest(http.MethodGet, "/api/users?limit=10&role=admin&role=user", nil)))
+	assert.NoError(t, validateRequest(t, validator, httptest.NewRequest(http.MethodGet, "/api/users/42", nil)))
+	err := validateRequest(t, validator, httptest.NewRequest(http.MethodGet, "/api/users?limit=0&role=guest", nil))
+	assert.Equal(t, ValidationErrors{
+		{In: "query", Name: "limit", Message: "must be greater than or equal to 1"},
+		{In: "query", Name: "role/0", Message: "must be one of [admin user]"},
+	}, err)
+	err = validateRequest(t, validator, httptest.NewRequest(http.MethodGet, "/api/users?limit=ten", nil))
+	assert.Equal(t, ValidationErrors{{In: "query", Name: "limit", Message: "must be an integer"}}, err)
+	err = validateRequest(t, validator, httptest.NewRequest(http.MethodGet, "/api/users/abc", nil))
+	assert.Equal(t, ValidationErrors{{In: "path", Name: "id", Message: "must be an integer"}}, err)
+	assert.EqualError(t, err, "path id: must be an integer")
+}
+
+func TestValidateBody(t *testing.T) {
+	validator := newTestValidator(t)
+	newRequest := func(body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		r.Header.Set("X-Request-ID", "6f1c2a3e-8d4b-4f5a-9c7e-2b1d0e3f4a5b")
+		return r
+	}
+	r := newRequest(`{"name":"John","email":"john@example.org","age":null,"tags":["a"],"contact":"phone"}`)
+	assert.NoError(t, validateRequest(t, validator, r))
+	body, err := io.ReadAll(r.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "John")
+	err = validateRequest(t, validator, newRequest(`{"name":"","age":-1,"tags":["a","B","c"],"contact":1,"extra":true}`))
+	assert.Equal(t, ValidationErrors{
+		{In: "body", Name: "/email", Message: "is required"},
+		{In: "body", Name: "/age", Message: "must be greater than or equal to 0"},
+		{In: "body", Name: "/contact", Message: "must match exactly one of the schemas"},
+		{In: "body", Name: "/extra", Message: "is not allowed"},
+		{In: "body", Name: "/name", Message: "must be at least 1 characters long"},
+		{In: "body", Name: "/tags", Message: "must have at most 2 items"},
+		{In: "body", Name: "/tags/1", Message: "must match pattern ^[a-z]+$"},
+	}, err)
+	err = validateRequest(t, validator, newRequest(`{"name":"John"`))
+	assert.Len(t, err, 1)
+	assert.Contains(t, err.Error(), "body: invalid JSON")
+	r = newRequest("")
+	r.Header.Del("X-Request-ID")
+	err = validateRequest(t, validator, r)
+	assert.Equal(t, ValidationErrors{
+		{In: "header", Name: "X-Request-ID", Message: "is required"},
+		{In: "body", Message: "is required"},
+	}, err)
+	r = newRequest("name=John")
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Request-ID", "123")
+	err = validateRequest(t, validator, r)
+	assert.Equal(t, ValidationErrors{
+		{In: "header", Name: "X-Request-ID", Message: "must be a valid uuid"},
+		{In: "header", Name: "Content-Type", Message: "media type is not supported: application/x-www-form-urlencoded"},
+	}, err)
+}
+
+func TestValidateResponse(t *testing.T) {
+	validator := newTestValidator(t)
+	operation, _ := validator.FindOperation(httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+	header := http.Header{"Content-Type": {"application/json"}}
+	assert.NoError(t, validator.ValidateResponse(operation, http.StatusOK, header, []byte(`{"name":"John","email":"john@example.org"}`)))
+	assert.Equal(t, ValidationErrors{{In: "body", Name: "/email", Message: "is required"}},
+		validator.ValidateResponse(operation, http.StatusPartialContent, header, []byte(`{"name":"John"}`)))
+	assert.Equal(t, ValidationErrors{{In: "status", Name: "404", Message: "is not described by the operation"}},
+		validator.ValidateResponse(operation, http.StatusNotFound, header, nil))
+}
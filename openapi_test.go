@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/goioc/web/openapi"
+	"github.com/goioc/web/swaggerui"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+type openAPIAudit struct {
+	Created time.Time `json:"created"`
+}
+
+type openAPIUser struct {
+	openAPIAudit
+	ID       int64             `json:"id" web.doc:"Unique identifier" web.example:"42"`
+	Name     string            `json:"name" web.example:"true"`
+	Tags     []string          `json:"tags,omitempty"`
+	Manager  *openAPIUser      `json:"manager,omitempty"`
+	Settings map[string]bool   `json:"settings,omitempty"`
+	Password string            `json:"-"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	Extra    map[string]string `json:",omitempty"`
+}
+
+type endpoint34 struct {
+	method  interface{} `web.methods:"PUT"`
+	path    interface{} `web.path:"/endpoint34/{id:[0-9]+}"`
+	queries interface{} `web.queries:"verbose,{verbose}"`
+	headers interface{} `web.headers:"X-Tenant,"`
+	docs    interface{} `web.summary:"Update user" web.description:"Replaces the user." web.tags:"users,admin" web.deprecated:"true"`
+}
+
+func (e endpoint34) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint34) REST(vars map[string]string, user openAPIUser) (int, *openAPIUser, error) {
+	return http.StatusOK, &user, nil
+}
+
+var openAPIConfig = &OpenAPIConfig{Info: openapi.Info{Title: "Test", Version: "1.0.0"}, Docs: swaggerui.Handler}
+
+func (suite *TestSuite) TestGenerateOpenAPI() {
+	document, err := GenerateOpenAPI("", openapi.Info{Title: "Test", Version: "1.0.0"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), openapi.Version, document.OpenAPI)
+	operation := document.Paths["/endpoint34/{id}"]["put"]
+	assert.NotNil(suite.T(), operation)
+	assert.Equal(suite.T(), "endpoint34", operation.OperationID)
+	assert.Equal(suite.T(), "Update user", operation.Summary)
+	assert.Equal(suite.T(), "Replaces the user.", operation.Description)
+	assert.Equal(suite.T(), []string{"users", "admin"}, operation.Tags)
+	assert.True(suite.T(), operation.Deprecated)
+	assert.Equal(suite.T(), []*openapi.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string", Pattern: "^[0-9]+$"}},
+		{Name: "verbose", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		{Name: "X-Tenant", In: "header", Required: true, Schema: &openapi.Schema{Type: "string"}},
+	}, operation.Parameters)
+	assert.Equal(suite.T(), openapi.SchemaRef("openAPIUser"), operation.RequestBody.Content["application/json"].Schema)
+	assert.Equal(suite.T(), openapi.SchemaRef("openAPIUser"), operation.Responses["default"].Content["application/json"].Schema)
+	assert.NotContains(suite.T(), operation.Responses, "500")
+	user := document.Components.Schemas["openAPIUser"]
+	assert.Equal(suite.T(), []string{"created", "id", "name"}, user.Required)
+	assert.Equal(suite.T(), &openapi.Schema{Type: "string", Format: "date-time"}, user.Properties["created"])
+	assert.Equal(suite.T(), &openapi.Schema{Type: "integer", Format: "int64", Description: "Unique identifier",
+		Examples: []interface{}{42.0}}, user.Properties["id"])
+	assert.Equal(suite.T(), []interface{}{"true"}, user.Properties["name"].Examples)
+	assert.Equal(suite.T(), &openapi.Schema{Type: "array", Items: &openapi.Schema{Type: "string"}}, user.Properties["tags"])
+	assert.Equal(suite.T(), openapi.SchemaRef("openAPIUser"), user.Properties["manager"])
+	assert.Equal(suite.T(), &openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{Type: "boolean"}},
+		user.Properties["settings"])
+	assert.Equal(suite.T(), &openapi.Schema{Type: "string", ContentEncoding: "base64"}, user.Properties["avatar"])
+	assert.NotContains(suite.T(), user.Properties, "Password")
+	assert.Contains(suite.T(), user.Properties, "Extra")
+	function := document.Paths["/function1/{id}"]["get"]
+	assert.Equal(suite.T(), "get_function1_id", function.OperationID)
+	assert.Equal(suite.T(), "Internal Server Error", function.Responses["500"].Description)
+	assert.Nil(suite.T(), document.Paths["/function3/{id}"]["get"].RequestBody)
+	assert.Equal(suite.T(), &openapi.Schema{Type: "integer", Format: "int64"},
+		document.Paths["/function3/{id}"]["get"].Parameters[0].Schema)
+	function4 := document.Paths["/function4/{name}/{version}"]["post"]
+	assert.NotNil(suite.T(), function4.RequestBody)
+	assert.Equal(suite.T(), &openapi.Schema{Type: "string"}, function4.Parameters[0].Schema)
+	minimum := 0.0
+	assert.Equal(suite.T(), &openapi.Schema{Type: "integer", Minimum: &minimum}, function4.Parameters[1].Schema)
+	json1 := document.Paths["/json1"]["post"]
+	assert.Equal(suite.T(), openapi.SchemaRef("jsonRequest"), json1.RequestBody.Content["application/json"].Schema)
+	assert.Equal(suite.T(), openapi.SchemaRef("jsonResponse"), json1.Responses["200"].Content["application/json"].Schema)
+	assert.Len(suite.T(), document.Paths["/function2"], len(allMethods))
+	assert.Equal(suite.T(), "string", document.Paths["/function2"]["post"].Responses["200"].Content["text/plain"].Schema.Type)
+	assert.Contains(suite.T(), document.Paths, "/group1/endpoint26")
+}
+
+func (suite *TestSuite) TestOpenAPIEndpoint() {
+	response, err := http.Get(server.URL + DefaultOpenAPIPath)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "application/json", response.Header.Get("Content-Type"))
+	var document openapi.Document
+	assert.NoError(suite.T(), json.NewDecoder(response.Body).Decode(&document))
+	assert.Equal(suite.T(), "Test", document.Info.Title)
+	assert.Contains(suite.T(), document.Paths, "/endpoint34/{id}")
+}
+
+func (suite *TestSuite) TestDocs() {
+	response, err := http.Get(server.URL + DefaultDocsPath)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), server.URL+DefaultDocsPath+"/", response.Request.URL.String())
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), string(all), `<script src="./swagger-ui-bundle.js"`)
+	response, err = http.Get(server.URL + DefaultDocsPath + "/swagger-initializer.js")
+	assert.NoError(suite.T(), err)
+	all, err = ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), string(all), `url: "/openapi.json"`)
+}
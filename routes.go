@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/goioc/di"
+	"github.com/gorilla/mux"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// GoiocRoutes is an ID for RoutesConfig bean. It's not registered by default: register your own instance to serve the
+// list of the routes of the server.
+const GoiocRoutes = "goiocRoutes"
+
+// DefaultRoutesPath is a default path the list of the routes is served at.
+const DefaultRoutesPath = "/routes"
+
+// RoutesConfig is a configuration of the admin endpoint serving the list of the routes in JSON.
+type RoutesConfig struct {
+	// Path is a path the list is served at. DefaultRoutesPath is used if empty.
+	Path string
+	// Server is a name of the server serving the list of its routes. Empty for the default server.
+	Server string
+	// Middleware is a list of middleware functions applied to the endpoint, e.g. to authorize the requests.
+	Middleware []mux.MiddlewareFunc
+	// Enabled is a function deciding whether to serve the list, e.g. to restrict it to non-production environments. The
+	// list is served if nil.
+	Enabled func() bool
+}
+
+// RouteInfo describes the route registered by the router.
+type RouteInfo struct {
+	// Name is a name of the route: bean ID for the endpoints, "<beanID>.<method>" for the routes of the controllers and
+	// HandlerFuncName (possibly empty) for the function endpoints.
+	Name string `json:"name,omitempty"`
+	// BeanID is an ID of the endpoint or the controller bean. Empty for the function endpoints.
+	BeanID string `json:"beanId,omitempty"`
+	// Methods is a list of HTTP methods of the route. Empty if the route matches any method.
+	Methods []string `json:"methods,omitempty"`
+	// Path is a path template of the route, including the prefix of the group.
+	Path string `json:"path"`
+	// Queries is a list of key-value pairs of the URL query part.
+	Queries []string `json:"queries,omitempty"`
+	// Headers is a list of key-value pairs of the request headers, including the ones required by the group.
+	Headers []string `json:"headers,omitempty"`
+	// Matchers is a list of IDs of the matcher beans of the group and the route.
+	Matchers []string `json:"matchers,omitempty"`
+	// Group is an ID of the group the route belongs to.
+	Group string `json:"group,omitempty"`
+	// Server is a name of the server the route belongs to. Empty for the default server.
+	Server string `json:"server,omitempty"`
+	// Handler is a name of the handler method, e.g. "(*app.getUser).Get", or function.
+	Handler string `json:"handler"`
+	// Signature is a signature of the handler, without the receiver.
+	Signature string `json:"signature"`
+	// Middleware is a chain of the middleware wrapping the handler, from the outermost one: names of the middleware
+	// functions and IDs of the middleware beans.
+	Middleware []string `json:"middleware,omitempty"`
+	// Summary is a short summary of the route.
+	Summary string `json:"summary,omitempty"`
+	// Description is a detailed description of the route.
+	Description string `json:"description,omitempty"`
+	// Tags is a list of tags of the route.
+	Tags []string `json:"tags,omitempty"`
+	// Deprecated flag marks the route as deprecated.
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// Routes function describes the routes of the server with the given name (empty for the default server) in the order
+// the router registers them: endpoints and controllers in the order of bean IDs, followed by function endpoints in the
+// order of registration. Routes of a group are matched together, at the position of the first route of the group, so
+// they take precedence over the routes outside the group listed after it. Built-in routes (OpenAPI document,
+// documentation page and the list of the routes) are not included. Can be called once the container is initialized.
+func Routes(serverName string) ([]RouteInfo, error) {
+	routes, err := describeRoutes(serverName)
+	if err != nil {
+		return nil, err
+	}
+	serverMiddleware := []string{functionName(di.Middleware)}
+	for _, middlewareFunction := range middlewareFunctionsInternal {
+		serverMiddleware = append(serverMiddleware, functionName(middlewareFunction))
+	}
+	middlewareBeanIDs, _, err := orderedMiddlewareBeans()
+	if err != nil {
+		return nil, err
+	}
+	serverMiddleware = append(serverMiddleware, middlewareBeanIDs...)
+	routeInfos := make([]RouteInfo, 0, len(routes))
+	for _, route := range routes {
+		routeInfo := RouteInfo{
+			Name:        route.name,
+			BeanID:      route.beanID,
+			Methods:     route.routeSpec.Methods,
+			Path:        route.path(),
+			Queries:     route.routeSpec.Queries,
+			Headers:     route.headers(),
+			Group:       route.routeSpec.Group,
+			Server:      route.routeSpec.Server,
+			Handler:     route.handler,
+			Signature:   handlerSignature(route.handlerFuncType, route.firstArgument),
+			Middleware:  append([]string(nil), serverMiddleware...),
+			Summary:     route.routeSpec.Summary,
+			Description: route.routeSpec.Description,
+			Tags:        route.routeSpec.Tags,
+			Deprecated:  route.routeSpec.Deprecated,
+		}
+		if route.group != nil {
+			if route.group.Matcher != "" {
+				routeInfo.Matchers = append(routeInfo.Matchers, route.group.Matcher)
+			}
+			for _, middlewareFunction := range route.group.Middleware {
+				routeInfo.Middleware = append(routeInfo.Middleware, functionName(middlewareFunction))
+			}
+		}
+		if route.routeSpec.Matcher != "" {
+			routeInfo.Matchers = append(routeInfo.Matchers, route.routeSpec.Matcher)
+		}
+		routeInfo.Middleware = append(routeInfo.Middleware, route.routeSpec.Middleware...)
+		routeInfos = append(routeInfos, routeInfo)
+	}
+	return routeInfos, nil
+}
+
+func registerRoutesHandler(router *mux.Router, serverName string) error {
+	if _, ok := di.GetBeanTypes()[GoiocRoutes]; !ok {
+		return nil
+	}
+	instance, err := di.GetInstanceSafe(GoiocRoutes)
+	if err != nil {
+		return err
+	}
+	config, ok := instance.(*RoutesConfig)
+	if !ok {
+		return errors.New("bean is not a routes config: " + GoiocRoutes)
+	}
+	if config.Server != serverName || config.Enabled != nil && !config.Enabled() {
+		return nil
+	}
+	routes, err := Routes(serverName)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(routes)
+	if err != nil {
+		return err
+	}
+	path := config.Path
+	if path == "" {
+		path = DefaultRoutesPath
+	}
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(content); err != nil {
+			panic(err)
+		}
+	})
+	for i := len(config.Middleware) - 1; i >= 0; i-- {
+		handler = config.Middleware[i](handler)
+	}
+	router.Methods(http.MethodGet).Path(path).Name(GoiocRoutes).Handler(handler)
+	return nil
+}
+
+// handlerSignature function returns the signature of the handler function type, skipping the receiver.
+func handlerSignature(handlerFuncType reflect.Type, firstArgument int) string {
+	arguments := make([]string, 0, handlerFuncType.NumIn())
+	for i := firstArgument; i < handlerFuncType.NumIn(); i++ {
+		argument := handlerFuncType.In(i).String()
+		if handlerFuncType.IsVariadic() && i == handlerFuncType.NumIn()-1 {
+			argument = "..." + handlerFuncType.In(i).Elem().String()
+		}
+		arguments = append(arguments, argument)
+	}
+	results := make([]string, 0, handlerFuncType.NumOut())
+	for i := 0; i < handlerFuncType.NumOut(); i++ {
+		results = append(results, handlerFuncType.Out(i).String())
+	}
+	signature := "func(" + strings.Join(arguments, ", ") + ")"
+	switch len(results) {
+	case 0:
+		return signature
+	case 1:
+		return signature + " " + results[0]
+	}
+	return signature + " (" + strings.Join(results, ", ") + ")"
+}
+
+// functionName function returns the fully qualified name of the function, e.g. "github.com/goioc/di.Middleware".
+func functionName(function interface{}) string {
+	value := reflect.ValueOf(function)
+	if value.Kind() != reflect.Func {
+		return value.Type().String()
+	}
+	if runtimeFunc := runtime.FuncForPC(value.Pointer()); runtimeFunc != nil {
+		return runtimeFunc.Name()
+	}
+	return value.Type().String()
+}
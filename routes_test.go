@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var routesConfig = &RoutesConfig{
+	Path: "/admin/routes",
+	Middleware: []mux.MiddlewareFunc{func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "admin" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}},
+}
+
+func findRoute(routes []RouteInfo, name string) RouteInfo {
+	for _, route := range routes {
+		if route.Name == name {
+			return route
+		}
+	}
+	return RouteInfo{}
+}
+
+func (suite *TestSuite) TestRoutes() {
+	routes, err := Routes("")
+	assert.NoError(suite.T(), err)
+	endpoint3 := findRoute(routes, "endpoint3")
+	assert.Equal(suite.T(), "endpoint3", endpoint3.BeanID)
+	assert.Equal(suite.T(), []string{"GET"}, endpoint3.Methods)
+	assert.Equal(suite.T(), "/endpoint3", endpoint3.Path)
+	assert.Equal(suite.T(), []string{"foo", "bar", "id", "{id:[0-9]+}"}, endpoint3.Queries)
+	assert.Equal(suite.T(), "(*web.endpoint3).REST", endpoint3.Handler)
+	assert.Equal(suite.T(), "func(url.Values) string", endpoint3.Signature)
+	assert.Equal(suite.T(), "github.com/goioc/di.Middleware", endpoint3.Middleware[0])
+	endpoint26 := findRoute(routes, "endpoint26")
+	assert.Equal(suite.T(), "/group1/endpoint26", endpoint26.Path)
+	assert.Equal(suite.T(), "group1", endpoint26.Group)
+	assert.Equal(suite.T(), functionName(group1.Middleware[0]), endpoint26.Middleware[len(endpoint26.Middleware)-1])
+	endpoint27 := findRoute(routes, "endpoint27")
+	assert.Equal(suite.T(), []string{"middleware1", "middleware2"}, endpoint27.Middleware[len(endpoint27.Middleware)-2:])
+	assert.Subset(suite.T(), endpoint27.Middleware, []string{"orderedMiddleware1", "orderedMiddleware2"})
+	controller := findRoute(routes, "controller1.Get")
+	assert.Equal(suite.T(), "controller1", controller.BeanID)
+	assert.Equal(suite.T(), "/controller1/{id:[0-9]+}", controller.Path)
+	assert.Equal(suite.T(), "func(map[string]string) string", controller.Signature)
+	function1 := findRoute(routes, "")
+	assert.Equal(suite.T(), "/function1/{id}", function1.Path)
+	assert.Equal(suite.T(), "github.com/goioc/web.function1", function1.Handler)
+	assert.Equal(suite.T(), "func(map[string]string, *web.testGreeter) (*web.functionResponse, error)", function1.Signature)
+	endpoint34 := findRoute(routes, "endpoint34")
+	assert.NotEmpty(suite.T(), endpoint34.Summary)
+	assert.NotEmpty(suite.T(), endpoint34.Tags)
+}
+
+func (suite *TestSuite) TestRoutesOrder() {
+	routes, err := Routes("")
+	assert.NoError(suite.T(), err)
+	var described []string
+	for _, route := range routes {
+		if route.Name != "" && route.Group == "" {
+			described = append(described, route.Name)
+		}
+	}
+	var registered []string
+	err = server.Config.Handler.(*mux.Router).Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		if name := route.GetName(); name != "" && len(ancestors) == 0 && !strings.HasPrefix(name, "goioc") {
+			registered = append(registered, name)
+		}
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), registered)
+	assert.Equal(suite.T(), described, registered)
+}
+
+func (suite *TestSuite) TestRoutesEndpoint() {
+	response, err := http.Get(server.URL + "/admin/routes")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusUnauthorized, response.StatusCode)
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/admin/routes", nil)
+	assert.NoError(suite.T(), err)
+	request.Header.Set("Authorization", "admin")
+	response, err = http.DefaultClient.Do(request)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "application/json", response.Header.Get("Content-Type"))
+	var routes []RouteInfo
+	assert.NoError(suite.T(), json.NewDecoder(response.Body).Decode(&routes))
+	expected, err := Routes("")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), len(expected), len(routes))
+	assert.Equal(suite.T(), "/endpoint3", findRoute(routes, "endpoint3").Path)
+}
+
+func (suite *TestSuite) TestHandlerSignature() {
+	assert.Equal(suite.T(), "func()", handlerSignature(reflect.TypeOf(func() {}), 0))
+	assert.Equal(suite.T(), "func(string, ...int) error", handlerSignature(reflect.TypeOf(func(string, ...int) error { return nil }), 0))
+	assert.Equal(suite.T(), "func(int) (string, error)", handlerSignature(reflect.TypeOf(func(bool, int) (string, error) { return "", nil }), 1))
+}
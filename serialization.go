@@ -37,6 +37,13 @@ type Serializer interface {
 	Deserialize([]byte, interface{}) error
 }
 
+// ContentTyper interface can be implemented by Serializer to declare the media type of the serialized objects. It's set
+// as Content-Type header of the responses, unless the handler sets the header itself.
+type ContentTyper interface {
+	// ContentType method returns the media type of the serialized objects, e.g. "application/json".
+	ContentType() string
+}
+
 // JsonSerializer is a default implementation of Serializer interface.
 type JsonSerializer struct {
 }
@@ -46,6 +53,11 @@ func (js JsonSerializer) Serialize(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
 }
 
+// ContentType method returns "application/json".
+func (js JsonSerializer) ContentType() string {
+	return "application/json"
+}
+
 // Deserialize method deserializes object from JSON.
 func (js JsonSerializer) Deserialize(data []byte, v interface{}) error {
 	return json.Unmarshal(data, v)
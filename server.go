@@ -0,0 +1,395 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/goioc/di"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultShutdownTimeout is a default duration Server waits for in-flight requests to complete on graceful shutdown.
+	DefaultShutdownTimeout = 30 * time.Second
+	// DefaultStartTimeout is a default duration Server waits for OnStart beans to start.
+	DefaultStartTimeout = 30 * time.Second
+)
+
+// ShutdownHook is a function executed by Server before or after the graceful shutdown.
+type ShutdownHook func(ctx context.Context) error
+
+// Server is a web server, automatically creating endpoints from registered beans. Unlike ListenAndServe function, it
+// allows to configure the underlying http.Server and shuts it down gracefully upon SIGINT or SIGTERM. Besides the
+// default server, it can run named servers (each on its own listener) serving the endpoints tagged with `web.server`.
+type Server struct {
+	// Addr is a TCP address of the default server to listen on.
+	Addr string
+	// Servers is a map of names of the additional servers to their TCP addresses to listen on.
+	Servers map[string]string
+	// ReadTimeout is a maximum duration for reading the entire request, including the body.
+	ReadTimeout time.Duration
+	// ReadHeaderTimeout is a maximum duration for reading the request headers.
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout is a maximum duration before timing out writes of the response.
+	WriteTimeout time.Duration
+	// IdleTimeout is a maximum duration to wait for the next request when keep-alives are enabled.
+	IdleTimeout time.Duration
+	// MaxHeaderBytes is a maximum number of bytes the server will read parsing the request headers.
+	MaxHeaderBytes int
+	// H2C flag enables serving HTTP/2 over cleartext connections (h2c), along with HTTP/1.
+	H2C bool
+	// TLSConfig is a TLS configuration used by ListenAndServeTLS (e.g. to set minimum version or cipher suites). If
+	// MinVersion is not set, TLS 1.2 is used.
+	TLSConfig *tls.Config
+	// ClientCAFile is a path to the PEM bundle of CAs used to verify client certificates (mutual TLS). If set and
+	// TLSConfig doesn't define ClientAuth, client certificates are required.
+	ClientCAFile string
+	// CertificateCheckInterval is a minimal interval between checks of the certificate files for changes.
+	// DefaultCertificateCheckInterval is used if zero.
+	CertificateCheckInterval time.Duration
+	// ShutdownTimeout is a maximum duration to wait for in-flight requests to complete on graceful shutdown triggered by
+	// a signal or by a failure of one of the servers. DefaultShutdownTimeout is used if zero.
+	ShutdownTimeout time.Duration
+	// StartTimeout is a maximum duration to wait for OnStart beans to start. DefaultStartTimeout is used if zero.
+	StartTimeout time.Duration
+	// Signals is a list of signals triggering graceful shutdown. SIGINT and SIGTERM are used if empty.
+	Signals []os.Signal
+	// PreShutdownHooks are executed before the server stops accepting new requests.
+	PreShutdownHooks []ShutdownHook
+	// PostShutdownHooks are executed after all in-flight requests are completed (or the shutdown timeout is exceeded)
+	// and OnStop beans are stopped.
+	PostShutdownHooks []ShutdownHook
+	lock              sync.Mutex
+	httpServers       map[string]*http.Server
+	beans             []interface{}
+	shutdownDone      chan struct{}
+}
+
+// NewServer function creates Server listening on the given address.
+func NewServer(addr string) *Server {
+	return &Server{Addr: addr}
+}
+
+// ListenAndServe method creates routers, starts the servers and blocks until they're shut down. Returns nil if the
+// servers were shut down gracefully.
+func (s *Server) ListenAndServe() error {
+	listeners, err := s.listen()
+	if err != nil {
+		return err
+	}
+	return s.ServeListeners(listeners)
+}
+
+// ListenAndServeTLS method is the same as ListenAndServe, but expects HTTPS connections. If certFile and keyFile are
+// provided, the certificate is reloaded once the files change. Otherwise, TLSConfig must provide the certificates.
+func (s *Server) ListenAndServeTLS(certFile, keyFile string) error {
+	tlsConfig, err := s.tlsConfig(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	listeners, err := s.listen()
+	if err != nil {
+		return err
+	}
+	return s.serve(listeners, tlsConfig, func(httpServer *http.Server, listener net.Listener) error {
+		return httpServer.ServeTLS(listener, "", "")
+	})
+}
+
+// Serve method is the same as ListenAndServe, but the default server accepts connections on the pre-opened listener
+// (e.g. created with ListenUnix or SystemdListeners) instead of Addr, and named servers are not started. The listener
+// is closed upon return.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.ServeListeners(map[string]net.Listener{"": listener})
+}
+
+// ServeListeners method is the same as Serve, but accepts a map of server names to the listeners. Empty name stands
+// for the default server.
+func (s *Server) ServeListeners(listeners map[string]net.Listener) error {
+	return s.serve(listeners, nil, func(httpServer *http.Server, listener net.Listener) error {
+		return httpServer.Serve(listener)
+	})
+}
+
+// Shutdown method gracefully shuts down the servers: executes pre-shutdown hooks, waits for in-flight requests to
+// complete (until ctx is done), stops OnStop beans and executes post-shutdown hooks. All the hooks are executed even if
+// some of them fail.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.lock.Lock()
+	httpServers, beans, shutdownDone := s.httpServers, s.beans, s.shutdownDone
+	s.httpServers, s.beans, s.shutdownDone = nil, nil, nil
+	s.lock.Unlock()
+	if httpServers == nil {
+		return errors.New("server is not running")
+	}
+	defer close(shutdownDone)
+	var errs []error
+	errs = append(errs, runShutdownHooks(ctx, s.PreShutdownHooks)...)
+	if err := shutdownHTTPServers(ctx, httpServers); err != nil {
+		errs = append(errs, err)
+	}
+	if err := stopBeans(ctx, beans); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, runShutdownHooks(ctx, s.PostShutdownHooks)...)
+	return errors.Join(errs...)
+}
+
+func (s *Server) tlsConfig(certFile, keyFile string) (*tls.Config, error) {
+	tlsConfig := new(tls.Config)
+	if s.TLSConfig != nil {
+		tlsConfig = s.TLSConfig.Clone()
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+	if s.ClientCAFile != "" {
+		clientCAs, err := loadCertPool(s.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = clientCAs
+		if tlsConfig.ClientAuth == tls.NoClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	if certFile == "" && keyFile == "" {
+		return tlsConfig, nil
+	}
+	certificateReloader, err := NewCertificateReloader(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	certificateReloader.CheckInterval = s.CertificateCheckInterval
+	tlsConfig.GetCertificate = certificateReloader.GetCertificate
+	return tlsConfig, nil
+}
+
+// routeServerNames function returns the sorted names of the named servers the endpoints, the controllers and the
+// function endpoints are tagged with.
+func routeServerNames() ([]string, error) {
+	endpointType := reflect.TypeOf((*Endpoint)(nil)).Elem()
+	controllerType := reflect.TypeOf((*Controller)(nil)).Elem()
+	servers := make(map[string]bool)
+	for beanID, beanType := range di.GetBeanTypes() {
+		if beanType.Implements(endpointType) {
+			routeSpec, err := endpointRouteSpec(beanType.Elem())
+			if err != nil {
+				return nil, err
+			}
+			servers[routeSpec.Server] = true
+		}
+		if beanType.Implements(controllerType) {
+			controller, _, err := beanResolver(beanID, beanType)
+			if err != nil {
+				return nil, err
+			}
+			for _, routeSpec := range controller.(Controller).Routes() {
+				servers[routeSpec.Server] = true
+			}
+		}
+	}
+	for _, functionEndpoint := range functionEndpoints {
+		servers[functionEndpoint.routeSpec.Server] = true
+	}
+	delete(servers, "")
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// unservedServers function returns the sorted names of the named servers the routes are tagged with, but which have no
+// listener, e.g. because they are missing from Server.Servers.
+func unservedServers(listeners map[string]net.Listener) ([]string, error) {
+	names, err := routeServerNames()
+	if err != nil {
+		return nil, err
+	}
+	var unserved []string
+	for _, name := range names {
+		if _, ok := listeners[name]; !ok {
+			unserved = append(unserved, name)
+		}
+	}
+	return unserved, nil
+}
+
+func (s *Server) listen() (map[string]net.Listener, error) {
+	addrs := map[string]string{"": s.Addr}
+	for name, addr := range s.Servers {
+		addrs[name] = addr
+	}
+	listeners := make(map[string]net.Listener)
+	for name, addr := range addrs {
+		if addr == "" {
+			addr = ":http"
+		}
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, errors.Join(err, closeListeners(listeners))
+		}
+		listeners[name] = listener
+	}
+	return listeners, nil
+}
+
+// serve method creates the routers and serves the listeners. TLS configuration (if any) is set before configuring h2c,
+// so that HTTP/2 is advertised via ALPN as well.
+func (s *Server) serve(listeners map[string]net.Listener, tlsConfig *tls.Config, serve func(*http.Server, net.Listener) error) error {
+	unserved, err := unservedServers(listeners)
+	if err != nil {
+		return errors.Join(err, closeListeners(listeners))
+	}
+	for _, name := range unserved {
+		logrus.WithField("server", name).Warn("Routes refer to a server that is not served, they are unavailable")
+	}
+	httpServers := make(map[string]*http.Server)
+	for name, listener := range listeners {
+		router, err := CreateServerRouter(name)
+		if err != nil {
+			return errors.Join(err, closeListeners(listeners))
+		}
+		httpServer := &http.Server{
+			Addr:              listener.Addr().String(),
+			Handler:           router,
+			ReadTimeout:       s.ReadTimeout,
+			ReadHeaderTimeout: s.ReadHeaderTimeout,
+			WriteTimeout:      s.WriteTimeout,
+			IdleTimeout:       s.IdleTimeout,
+			MaxHeaderBytes:    s.MaxHeaderBytes,
+		}
+		if tlsConfig != nil {
+			httpServer.TLSConfig = tlsConfig.Clone()
+		}
+		if s.H2C {
+			if err := configureH2C(httpServer); err != nil {
+				return errors.Join(err, closeListeners(listeners))
+			}
+		}
+		httpServers[name] = httpServer
+	}
+	startCtx, cancel := context.WithTimeout(context.Background(), durationOrDefault(s.StartTimeout, DefaultStartTimeout))
+	beans, err := startBeans(startCtx, durationOrDefault(s.ShutdownTimeout, DefaultShutdownTimeout))
+	cancel()
+	if err != nil {
+		return errors.Join(err, closeListeners(listeners))
+	}
+	shutdownDone := make(chan struct{})
+	s.lock.Lock()
+	s.httpServers, s.beans, s.shutdownDone = httpServers, beans, shutdownDone
+	s.lock.Unlock()
+	signals := make(chan os.Signal, 1)
+	if len(s.Signals) > 0 {
+		signal.Notify(signals, s.Signals...)
+	} else {
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	}
+	defer signal.Stop(signals)
+	errs := make(chan error, len(listeners))
+	for name, listener := range listeners {
+		go func(httpServer *http.Server, listener net.Listener) {
+			errs <- serve(httpServer, listener)
+		}(httpServers[name], listener)
+	}
+	select {
+	case err := <-errs:
+		if errors.Is(err, http.ErrServerClosed) {
+			<-shutdownDone
+			return nil
+		}
+		logrus.WithError(err).Error("Server failed, shutting down the rest...")
+		return errors.Join(err, s.shutdownWithTimeout())
+	case sig := <-signals:
+		logrus.WithField("signal", sig).Info("Shutting down the server...")
+		return s.shutdownWithTimeout()
+	}
+}
+
+func (s *Server) shutdownWithTimeout() error {
+	ctx, cancel := context.WithTimeout(context.Background(), durationOrDefault(s.ShutdownTimeout, DefaultShutdownTimeout))
+	defer cancel()
+	return s.Shutdown(ctx)
+}
+
+// configureH2C wraps the server's handler to serve h2c. HTTP/2 server is registered within the http.Server, so that
+// HTTP/2 connections are shut down gracefully as well.
+func configureH2C(httpServer *http.Server) error {
+	http2Server := &http2.Server{IdleTimeout: httpServer.IdleTimeout}
+	if err := http2.ConfigureServer(httpServer, http2Server); err != nil {
+		return err
+	}
+	httpServer.Handler = h2c.NewHandler(httpServer.Handler, http2Server)
+	return nil
+}
+
+func shutdownHTTPServers(ctx context.Context, httpServers map[string]*http.Server) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, len(httpServers))
+	for _, httpServer := range httpServers {
+		wg.Add(1)
+		go func(httpServer *http.Server) {
+			defer wg.Done()
+			errs <- httpServer.Shutdown(ctx)
+		}(httpServer)
+	}
+	wg.Wait()
+	close(errs)
+	var joined []error
+	for err := range errs {
+		joined = append(joined, err)
+	}
+	return errors.Join(joined...)
+}
+
+func closeListeners(listeners map[string]net.Listener) error {
+	var errs []error
+	for _, listener := range listeners {
+		errs = append(errs, listener.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func runShutdownHooks(ctx context.Context, hooks []ShutdownHook) []error {
+	var errs []error
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func durationOrDefault(duration, defaultDuration time.Duration) time.Duration {
+	if duration == 0 {
+		return defaultDuration
+	}
+	return duration
+}
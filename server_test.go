@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+func freeAddr() string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func waitForServer(url string) (*http.Response, error) {
+	var response *http.Response
+	var err error
+	for i := 0; i < 100; i++ {
+		if response, err = http.Get(url); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return response, err
+}
+
+type endpoint28 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint28"`
+	server interface{} `web.server:"admin"`
+}
+
+func (e endpoint28) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint28) REST() string {
+	return "admin"
+}
+
+func (suite *TestSuite) TestServer() {
+	addr := freeAddr()
+	var hooks []string
+	testServer := NewServer(addr)
+	testServer.ReadHeaderTimeout = time.Second
+	testServer.PreShutdownHooks = []ShutdownHook{func(ctx context.Context) error {
+		hooks = append(hooks, "pre")
+		return nil
+	}}
+	testServer.PostShutdownHooks = []ShutdownHook{func(ctx context.Context) error {
+		hooks = append(hooks, "post")
+		return nil
+	}}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- testServer.ListenAndServe()
+	}()
+	response, err := waitForServer("http://" + addr + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test", string(all))
+	assert.NoError(suite.T(), testServer.Shutdown(context.Background()))
+	assert.NoError(suite.T(), <-errs)
+	assert.Equal(suite.T(), []string{"pre", "post"}, hooks)
+	assert.Error(suite.T(), testServer.Shutdown(context.Background()))
+}
+
+func (suite *TestSuite) TestNamedServers() {
+	addr, adminAddr := freeAddr(), freeAddr()
+	testServer := NewServer(addr)
+	testServer.Servers = map[string]string{"admin": adminAddr}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- testServer.ListenAndServe()
+	}()
+	response, err := waitForServer("http://" + adminAddr + "/endpoint28")
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "admin", string(all))
+	response, err = http.Get("http://" + adminAddr + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNotFound, response.StatusCode)
+	response, err = http.Get("http://" + addr + "/endpoint28")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNotFound, response.StatusCode)
+	response, err = http.Get("http://" + addr + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, response.StatusCode)
+	assert.NoError(suite.T(), testServer.Shutdown(context.Background()))
+	assert.NoError(suite.T(), <-errs)
+}
+
+func (suite *TestSuite) TestUnservedServers() {
+	unserved, err := unservedServers(map[string]net.Listener{"": nil})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"admin"}, unserved)
+	unserved, err = unservedServers(map[string]net.Listener{"": nil, "admin": nil})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), unserved)
+}
+
+func (suite *TestSuite) TestH2C() {
+	addr := freeAddr()
+	testServer := NewServer(addr)
+	testServer.H2C = true
+	errs := make(chan error, 1)
+	go func() {
+		errs <- testServer.ListenAndServe()
+	}()
+	_, err := waitForServer("http://" + addr + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	client := http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return new(net.Dialer).DialContext(ctx, network, addr)
+		},
+	}}
+	response, err := client.Get("http://" + addr + "/endpoint1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, response.ProtoMajor)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test", string(all))
+	assert.NoError(suite.T(), testServer.Shutdown(context.Background()))
+	assert.NoError(suite.T(), <-errs)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+// Package swaggerui serves Swagger UI, the interactive API documentation page, from the embedded assets, so it works
+// offline. It's a separate package, so that the applications not using it don't carry the assets.
+package swaggerui
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed assets
+var assets embed.FS
+
+const indexPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>API documentation</title>
+    <link rel="stylesheet" type="text/css" href="./swagger-ui.css"/>
+    <link rel="icon" type="image/png" href="./favicon-32x32.png" sizes="32x32"/>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="./swagger-ui-bundle.js" charset="UTF-8"></script>
+<script src="./swagger-initializer.js" charset="UTF-8"></script>
+</body>
+</html>
+`
+
+const initializerScript = `window.onload = function () {
+    window.ui = SwaggerUIBundle({
+        url: %s,
+        dom_id: "#swagger-ui",
+        deepLinking: true,
+        presets: [SwaggerUIBundle.presets.apis],
+        layout: "BaseLayout"
+    });
+};
+`
+
+// Handler function returns http handler serving Swagger UI page for the OpenAPI document at specURL. The page refers
+// to its assets relatively, so the handler can be mounted at any path prefix (with the trailing slash), e.g.:
+//
+//	router.PathPrefix("/docs/").Handler(http.StripPrefix("/docs", swaggerui.Handler("/openapi.json")))
+func Handler(specURL string) http.Handler {
+	files, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.FileServer(http.FS(files))
+	url, err := json.Marshal(specURL)
+	if err != nil {
+		panic(err)
+	}
+	initializer := []byte(fmt.Sprintf(initializerScript, url))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "", "/", "/index.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if _, err := w.Write([]byte(indexPage)); err != nil {
+				panic(err)
+			}
+		case "/swagger-initializer.js":
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			if _, err := w.Write(initializer); err != nil {
+				panic(err)
+			}
+		default:
+			fileServer.ServeHTTP(w, r)
+		}
+	})
+}
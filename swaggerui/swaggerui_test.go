@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package swaggerui
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	handler := Handler("/api/openapi.json")
+	for path, contentType := range map[string]string{
+		"/":                       "text/html; charset=utf-8",
+		"/swagger-initializer.js": "text/javascript; charset=utf-8",
+		"/swagger-ui-bundle.js":   "text/javascript; charset=utf-8",
+		"/swagger-ui.css":         "text/css; charset=utf-8",
+		"/favicon-32x32.png":      "image/png",
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code, path)
+		assert.Equal(t, contentType, recorder.Header().Get("Content-Type"), path)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/swagger-initializer.js", nil))
+	assert.Contains(t, recorder.Body.String(), `url: "/api/openapi.json"`)
+}
+
+func TestAssetsLicense(t *testing.T) {
+	license, err := fs.ReadFile(assets, "assets/LICENSE")
+	assert.NoError(t, err)
+	assert.Contains(t, string(license), "Apache License\n                           Version 2.0, January 2004")
+	notice, err := fs.ReadFile(assets, "assets/NOTICE")
+	assert.NoError(t, err)
+	assert.Contains(t, string(notice), "LICENSE file")
+}
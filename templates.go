@@ -0,0 +1,235 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"errors"
+	"github.com/goioc/di"
+	"github.com/sirupsen/logrus"
+	htmlTemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// GoiocTemplateRegistry is an ID for TemplateRegistry bean. It's not registered by default: register your own instance
+// (e.g. created with NewTemplateRegistry) under this ID to render web.View results that don't specify Templates. The
+// registry is loaded by CreateRouter.
+const GoiocTemplateRegistry = "goiocTemplateRegistry"
+
+// TemplateFunctions is an interface marking beans that contribute functions to the templates of TemplateRegistry.
+type TemplateFunctions interface {
+	// FuncMap method returns functions that will be available in the templates.
+	FuncMap() htmlTemplate.FuncMap
+}
+
+// TemplateRegistry is a set of html/template pages sharing common layouts and partials. Every page is parsed together
+// with all the layouts and partials, so pages can (re)define blocks declared in the layout. Templates are addressed by
+// their slash-separated path relative to the root of FS (e.g. "pages/todo.html").
+type TemplateRegistry struct {
+	// FS is a file system to load templates from: os.DirFS(...) or embed.FS.
+	FS fs.FS
+	// Pages is a glob pattern matching page templates.
+	Pages string
+	// Layouts is a glob pattern matching layout templates.
+	Layouts string
+	// Partials is a glob pattern matching partial templates.
+	Partials string
+	// Layout is a name of the layout template to execute when rendering a page. If empty, the page itself is executed.
+	Layout string
+	// Development flag makes the registry re-parse templates from FS on each render. Template functions are collected
+	// once, by Load.
+	Development bool
+	lock        sync.RWMutex
+	funcMap     htmlTemplate.FuncMap
+	pages       map[string]*htmlTemplate.Template
+}
+
+// NewTemplateRegistry function creates TemplateRegistry loading pages matching the pattern from the file system.
+func NewTemplateRegistry(fsys fs.FS, pages string) *TemplateRegistry {
+	return &TemplateRegistry{FS: fsys, Pages: pages}
+}
+
+// NewTemplateRegistryFromDir function creates TemplateRegistry loading pages matching the pattern from the directory.
+func NewTemplateRegistryFromDir(dir string, pages string) *TemplateRegistry {
+	return NewTemplateRegistry(os.DirFS(dir), pages)
+}
+
+// Load method (re)loads all the templates from FS, along with the functions of TemplateFunctions beans.
+func (tr *TemplateRegistry) Load() error {
+	funcMap, err := templateFunctions()
+	if err != nil {
+		return err
+	}
+	pages, err := tr.parse(funcMap)
+	if err != nil {
+		return err
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.funcMap = funcMap
+	tr.pages = pages
+	return nil
+}
+
+// reload method re-parses the templates from FS, reusing the functions collected by Load.
+func (tr *TemplateRegistry) reload() error {
+	tr.lock.RLock()
+	funcMap := tr.funcMap
+	tr.lock.RUnlock()
+	if funcMap == nil {
+		return tr.Load()
+	}
+	pages, err := tr.parse(funcMap)
+	if err != nil {
+		return err
+	}
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+	tr.pages = pages
+	return nil
+}
+
+// ExecuteTemplate method renders the page with the given name to the writer, using data to fill it in.
+func (tr *TemplateRegistry) ExecuteTemplate(wr io.Writer, name string, data interface{}) error {
+	if tr.Development {
+		if err := tr.reload(); err != nil {
+			return err
+		}
+	}
+	tr.lock.RLock()
+	page, ok := tr.pages[name]
+	tr.lock.RUnlock()
+	if !ok {
+		return errors.New("template is not registered: " + name)
+	}
+	if tr.Layout != "" {
+		return page.ExecuteTemplate(wr, tr.Layout, data)
+	}
+	return page.ExecuteTemplate(wr, name, data)
+}
+
+func (tr *TemplateRegistry) parse(funcMap htmlTemplate.FuncMap) (map[string]*htmlTemplate.Template, error) {
+	base := htmlTemplate.New("").Funcs(funcMap)
+	for _, pattern := range []string{tr.Layouts, tr.Partials} {
+		if err := parseFiles(base, tr.FS, pattern); err != nil {
+			return nil, err
+		}
+	}
+	names, err := fs.Glob(tr.FS, tr.Pages)
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]*htmlTemplate.Template)
+	for _, name := range names {
+		page, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if err := parseFile(page, tr.FS, name); err != nil {
+			return nil, err
+		}
+		pages[name] = page
+		logrus.WithField("name", name).Trace("Template loaded")
+	}
+	return pages, nil
+}
+
+func loadTemplateRegistry() error {
+	if _, ok := di.GetBeanTypes()[GoiocTemplateRegistry]; !ok {
+		return nil
+	}
+	templateRegistry, err := di.GetInstanceSafe(GoiocTemplateRegistry)
+	if err != nil {
+		return err
+	}
+	return loadTemplateSet(templateRegistry)
+}
+
+// templateRegistry function returns GoiocTemplateRegistry bean, checking that it's a TemplateSet.
+func templateRegistry() (TemplateSet, error) {
+	instance, err := di.GetInstanceSafe(GoiocTemplateRegistry)
+	if err != nil {
+		return nil, err
+	}
+	templateSet, ok := instance.(TemplateSet)
+	if !ok {
+		return nil, errors.New("bean is not a template set: " + GoiocTemplateRegistry)
+	}
+	return templateSet, nil
+}
+
+// loadTemplateSet function loads the templates of the registry bean if it supports loading, like TemplateRegistry
+// does. Other TemplateSet implementations are used as is.
+func loadTemplateSet(templateRegistry interface{}) error {
+	if loader, ok := templateRegistry.(interface{ Load() error }); ok {
+		return loader.Load()
+	}
+	if _, ok := templateRegistry.(TemplateSet); !ok {
+		return errors.New("bean is not a template set: " + GoiocTemplateRegistry)
+	}
+	return nil
+}
+
+func parseFiles(tmpl *htmlTemplate.Template, fsys fs.FS, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := parseFile(tmpl, fsys, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseFile(tmpl *htmlTemplate.Template, fsys fs.FS, name string) error {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	_, err = tmpl.New(name).Parse(string(content))
+	return err
+}
+
+func templateFunctions() (htmlTemplate.FuncMap, error) {
+	templateFunctionsType := reflect.TypeOf((*TemplateFunctions)(nil)).Elem()
+	beanScopes := di.GetBeanScopes()
+	var beanIDs []string
+	for beanID, beanType := range di.GetBeanTypes() {
+		if beanType.Implements(templateFunctionsType) && beanScopes[beanID] == di.Singleton {
+			beanIDs = append(beanIDs, beanID)
+		}
+	}
+	sort.Strings(beanIDs)
+	funcMap := make(htmlTemplate.FuncMap)
+	for _, beanID := range beanIDs {
+		instance, err := di.GetInstanceSafe(beanID)
+		if err != nil {
+			return nil, err
+		}
+		for name, function := range instance.(TemplateFunctions).FuncMap() {
+			funcMap[name] = function
+		}
+	}
+	return funcMap, nil
+}
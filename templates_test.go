@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	htmlTemplate "html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing/fstest"
+)
+
+var templatesFS = fstest.MapFS{
+	"layouts/base.html":  {Data: []byte(`<title>{{block "title" .}}default{{end}}</title>{{template "content" .}}`)},
+	"partials/item.html": {Data: []byte(`{{define "item"}}<li>{{shout .Title}}</li>{{end}}`)},
+	"pages/todo.html":    {Data: []byte(`{{define "title"}}{{.PageTitle}}{{end}}{{define "content"}}{{range .Todos}}{{template "item" .}}{{end}}{{end}}`)},
+	"pages/empty.html":   {Data: []byte(`{{define "content"}}empty{{end}}`)},
+}
+
+func newTestTemplateRegistry() *TemplateRegistry {
+	templateRegistry := NewTemplateRegistry(templatesFS, "pages/*.html")
+	templateRegistry.Layouts = "layouts/*.html"
+	templateRegistry.Partials = "partials/*.html"
+	templateRegistry.Layout = "layouts/base.html"
+	return templateRegistry
+}
+
+type shoutFunctions struct {
+}
+
+func (sf *shoutFunctions) FuncMap() htmlTemplate.FuncMap {
+	return htmlTemplate.FuncMap{"shout": strings.ToUpper}
+}
+
+type endpoint23 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint23"`
+}
+
+func (e endpoint23) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint23) REST() View {
+	return View{
+		Name: "pages/todo.html",
+		Model: todoPageData{
+			PageTitle: "My TODO list",
+			Todos:     []todo{{Title: "Task 1"}, {Title: "Task 2"}},
+		},
+	}
+}
+
+type failingModel struct {
+}
+
+func (fm failingModel) Fail() (string, error) {
+	return "", errors.New("rendering failed")
+}
+
+var failingTemplate = htmlTemplate.Must(htmlTemplate.New("failing").Parse(`before{{.Fail}}after`))
+
+type endpoint24 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint24"`
+}
+
+func (e endpoint24) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint24) REST() (int, View) {
+	return http.StatusCreated, View{Templates: failingTemplate, Name: "failing", Model: failingModel{}}
+}
+
+type endpoint25 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint25"`
+}
+
+func (e endpoint25) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint25) REST() (int, View) {
+	return http.StatusAccepted, View{Templates: templateSet, Name: "header", Model: "streamed", Stream: true}
+}
+
+func (suite *TestSuite) TestEndpoint23() {
+	response, err := http.Get(server.URL + "/endpoint23")
+	assert.NotNil(suite.T(), response)
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "<title>My TODO list</title><li>TASK 1</li><li>TASK 2</li>", string(all))
+}
+
+func (suite *TestSuite) TestTemplateRegistry() {
+	templateRegistry := newTestTemplateRegistry()
+	assert.NoError(suite.T(), templateRegistry.Load())
+	buf := new(bytes.Buffer)
+	assert.NoError(suite.T(), templateRegistry.ExecuteTemplate(buf, "pages/empty.html", nil))
+	assert.Equal(suite.T(), "<title>default</title>empty", buf.String())
+	assert.Error(suite.T(), templateRegistry.ExecuteTemplate(buf, "pages/missing.html", nil))
+}
+
+func (suite *TestSuite) TestLoadTemplateSet() {
+	assert.NoError(suite.T(), loadTemplateSet(htmlTemplate.Must(htmlTemplate.New("page").Parse("page"))))
+	assert.NoError(suite.T(), loadTemplateSet(newTestTemplateRegistry()))
+	assert.Error(suite.T(), loadTemplateSet(NewTemplateRegistry(templatesFS, "missing/[")))
+	assert.EqualError(suite.T(), loadTemplateSet(&struct{}{}), "bean is not a template set: "+GoiocTemplateRegistry)
+}
+
+func (suite *TestSuite) TestRenderStreamFailure() {
+	recorder := httptest.NewRecorder()
+	assert.PanicsWithValue(suite.T(), http.ErrAbortHandler, func() {
+		render(recorder, func() {}, true, func(wr io.Writer) error {
+			return failingTemplate.Execute(wr, failingModel{})
+		})
+	})
+	assert.Equal(suite.T(), "before", recorder.Body.String())
+}
+
+func (suite *TestSuite) TestRenderView() {
+	recorder := httptest.NewRecorder()
+	renderView(recorder, func() {}, View{Name: "pages/empty.html"}, nil, errors.New("no registry"))
+	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
+	registry, err := templateRegistry()
+	assert.NoError(suite.T(), err)
+	recorder = httptest.NewRecorder()
+	renderView(recorder, func() {}, View{Name: "pages/empty.html"}, registry, nil)
+	assert.Equal(suite.T(), "<title>default</title>empty", recorder.Body.String())
+}
+
+func (suite *TestSuite) TestTemplateRegistryDevelopment() {
+	fsys := fstest.MapFS{"page.html": {Data: []byte(`v1`)}}
+	templateRegistry := NewTemplateRegistry(fsys, "*.html")
+	templateRegistry.Development = true
+	buf := new(bytes.Buffer)
+	assert.NoError(suite.T(), templateRegistry.ExecuteTemplate(buf, "page.html", nil))
+	assert.Equal(suite.T(), "v1", buf.String())
+	fsys["page.html"] = &fstest.MapFile{Data: []byte(`v2`)}
+	buf.Reset()
+	assert.NoError(suite.T(), templateRegistry.ExecuteTemplate(buf, "page.html", nil))
+	assert.Equal(suite.T(), "v2", buf.String())
+}
+
+func (suite *TestSuite) TestTemplateRegistryDevelopmentFunctions() {
+	fsys := fstest.MapFS{"page.html": {Data: []byte(`{{ shout "v1" }}`)}}
+	templateRegistry := NewTemplateRegistry(fsys, "*.html")
+	templateRegistry.Development = true
+	assert.NoError(suite.T(), templateRegistry.Load())
+	templateRegistry.funcMap = htmlTemplate.FuncMap{"shout": strings.ToLower, "whisper": strings.ToLower}
+	fsys["page.html"] = &fstest.MapFile{Data: []byte(`{{ whisper "V2" }}`)}
+	buf := new(bytes.Buffer)
+	assert.NoError(suite.T(), templateRegistry.ExecuteTemplate(buf, "page.html", nil))
+	assert.Equal(suite.T(), "v2", buf.String())
+}
+
+func (suite *TestSuite) TestEndpoint24() {
+	response, err := http.Get(server.URL + "/endpoint24")
+	assert.NotNil(suite.T(), response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusInternalServerError, response.StatusCode)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Internal Server Error\n", string(all))
+}
+
+func (suite *TestSuite) TestEndpoint25() {
+	response, err := http.Get(server.URL + "/endpoint25")
+	assert.NotNil(suite.T(), response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusAccepted, response.StatusCode)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "<h1>streamed</h1>", string(all))
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/goioc/web/openapi"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+// ValidationOptions is a configuration of the middleware validating requests against OpenAPI document.
+type ValidationOptions struct {
+	// Responses flag enables validation of the responses. Such responses are buffered, and the invalid ones are logged
+	// and replaced with 500 Internal Server Error.
+	Responses bool
+	// RejectUnknown flag makes the middleware respond with 404 Not Found to the requests not described by the document
+	// (or with 405 Method Not Allowed and Allow header if the document describes the path, but not the method).
+	// Otherwise, such requests are passed through without validation.
+	RejectUnknown bool
+}
+
+// ValidationResponse is a body of the response to the request rejected by the validation middleware.
+type ValidationResponse struct {
+	Message string                   `json:"message"`
+	Errors  openapi.ValidationErrors `json:"errors,omitempty"`
+}
+
+// ValidationMiddleware function creates middleware validating the path, query and header parameters and the body of
+// the requests against the OpenAPI document (e.g. loaded with openapi.Load). Invalid requests are rejected with 400 Bad
+// Request and ValidationResponse in JSON, without calling the handler.
+func ValidationMiddleware(document *openapi.Document, options ValidationOptions) (mux.MiddlewareFunc, error) {
+	validator, err := openapi.NewValidator(document)
+	if err != nil {
+		return nil, err
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			operation, pathParameters := validator.FindOperation(r)
+			if operation == nil {
+				if options.RejectUnknown {
+					if methods := validator.AllowedMethods(r); len(methods) > 0 {
+						w.Header().Set("Allow", strings.Join(methods, ", "))
+						writeValidationResponse(w, http.StatusMethodNotAllowed, nil)
+						return
+					}
+					writeValidationResponse(w, http.StatusNotFound, nil)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := validator.ValidateRequest(r, operation, pathParameters); err != nil {
+				validationErrors, ok := err.(openapi.ValidationErrors)
+				if !ok {
+					WriteError(w, err)
+					return
+				}
+				writeValidationResponse(w, http.StatusBadRequest, validationErrors)
+				return
+			}
+			if !options.Responses {
+				next.ServeHTTP(w, r)
+				return
+			}
+			buffer := &responseBuffer{header: make(http.Header), statusCode: http.StatusOK}
+			next.ServeHTTP(buffer, r)
+			if _, ok := buffer.header["Content-Type"]; !ok && buffer.body.Len() > 0 {
+				// the same media type net/http would detect when writing the response
+				buffer.header.Set("Content-Type", http.DetectContentType(buffer.body.Bytes()))
+			}
+			if err := validator.ValidateResponse(operation, buffer.statusCode, buffer.header, buffer.body.Bytes()); err != nil {
+				logrus.WithError(err).WithField("path", r.URL.Path).Error("Response doesn't match OpenAPI document")
+				writeValidationResponse(w, http.StatusInternalServerError, nil)
+				return
+			}
+			for key, values := range buffer.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(buffer.statusCode)
+			if _, err := w.Write(buffer.body.Bytes()); err != nil {
+				panic(err)
+			}
+		})
+	}, nil
+}
+
+func writeValidationResponse(w http.ResponseWriter, statusCode int, validationErrors openapi.ValidationErrors) {
+	body, err := json.Marshal(ValidationResponse{Message: http.StatusText(statusCode), Errors: validationErrors})
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		panic(err)
+	}
+}
+
+// responseBuffer is http.ResponseWriter keeping the response in memory until it is validated.
+type responseBuffer struct {
+	header      http.Header
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rb *responseBuffer) Header() http.Header {
+	return rb.header
+}
+
+func (rb *responseBuffer) WriteHeader(statusCode int) {
+	if !rb.wroteHeader {
+		rb.statusCode = statusCode
+		rb.wroteHeader = true
+	}
+}
+
+func (rb *responseBuffer) Write(data []byte) (int, error) {
+	rb.wroteHeader = true
+	return rb.body.Write(data)
+}
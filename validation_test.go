@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/goioc/web/openapi"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+const validationDocument = `
+openapi: 3.1.0
+info:
+  title: Validation
+  version: 1.0.0
+paths:
+  /items/{id}:
+    put:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+      responses:
+        200:
+          description: Item
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+`
+
+func newValidationRequest(method string, target string, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
+
+func (suite *TestSuite) validationRouter(options ValidationOptions) *mux.Router {
+	document, err := openapi.Parse([]byte(validationDocument))
+	suite.NoError(err)
+	middleware, err := ValidationMiddleware(document, options)
+	suite.NoError(err)
+	router := mux.NewRouter()
+	router.Use(middleware)
+	router.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(r.URL.Query().Get("response")))
+	})
+	return router
+}
+
+func (suite *TestSuite) TestValidationMiddleware() {
+	router := suite.validationRouter(ValidationOptions{})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, newValidationRequest(http.MethodPut, "/items/1?response=ok", `{"name":"item"}`))
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("ok", recorder.Body.String())
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, newValidationRequest(http.MethodPut, "/items/one", `{"name":1}`))
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal("application/json", recorder.Header().Get("Content-Type"))
+	var response ValidationResponse
+	suite.NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	suite.Equal(ValidationResponse{
+		Message: "Bad Request",
+		Errors: openapi.ValidationErrors{
+			{In: "path", Name: "id", Message: "must be an integer"},
+			{In: "body", Name: "/name", Message: "must be a string"},
+		},
+	}, response)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/unknown?response=ok", nil))
+	suite.Equal(http.StatusOK, recorder.Code)
+}
+
+const functionsDocument = `
+openapi: 3.1.0
+info:
+  title: Functions
+  version: 1.0.0
+paths:
+  /function3/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        200:
+          description: Function response
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [ID, Greeting]
+  /function2:
+    post:
+      responses:
+        200:
+          description: Uppercase body
+          content:
+            text/plain:
+              schema:
+                type: string
+`
+
+func (suite *TestSuite) TestValidationMiddlewareEndpoints() {
+	document, err := openapi.Parse([]byte(functionsDocument))
+	suite.NoError(err)
+	middleware, err := ValidationMiddleware(document, ValidationOptions{Responses: true})
+	suite.NoError(err)
+	handler := middleware(server.Config.Handler)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/function3/41", nil))
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("application/json", recorder.Header().Get("Content-Type"))
+	suite.JSONEq(`{"ID":"42","Greeting":""}`, recorder.Body.String())
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/function2", strings.NewReader("text")))
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("TEXT", recorder.Body.String())
+}
+
+func (suite *TestSuite) TestValidationMiddlewareOptions() {
+	router := suite.validationRouter(ValidationOptions{Responses: true, RejectUnknown: true})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	suite.Equal(http.StatusNotFound, recorder.Code)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/items/1", nil))
+	suite.Equal(http.StatusMethodNotAllowed, recorder.Code)
+	suite.Equal("PUT", recorder.Header().Get("Allow"))
+	suite.JSONEq(`{"message":"Method Not Allowed"}`, recorder.Body.String())
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, newValidationRequest(http.MethodPut, `/items/1?response={"id":1}`, `{"name":"item"}`))
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("application/json", recorder.Header().Get("Content-Type"))
+	suite.Equal(`{"id":1}`, recorder.Body.String())
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, newValidationRequest(http.MethodPut, `/items/1?response={}`, `{"name":"item"}`))
+	suite.Equal(http.StatusInternalServerError, recorder.Code)
+	suite.JSONEq(`{"message":"Internal Server Error"}`, recorder.Body.String())
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2024 Go IoC
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ */
+
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxPooledBufferSize limits the capacity of render buffers returned to the pool, so that a single huge page doesn't
+// pin its memory forever.
+const maxPooledBufferSize = 1 << 20
+
+var renderBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// TemplateSet is an interface representing a parsed tree of named templates. Both *html/template.Template and
+// *text/template.Template implement it.
+type TemplateSet interface {
+	// ExecuteTemplate method applies the template with the given name to the specified data object.
+	ExecuteTemplate(wr io.Writer, name string, data interface{}) error
+}
+
+// View is a return type that renders a named template from the template set, using Model to fill it in.
+type View struct {
+	// Templates is a template set containing the template to render. If nil, GoiocTemplateRegistry bean is used.
+	Templates TemplateSet
+	// Name is a name of the template within the set.
+	Name string
+	// Model is an object that will be used to fill in the template.
+	Model interface{}
+	// Stream flag makes the template render directly to the response instead of the buffer. It saves memory for very
+	// large pages, but rendering failure can't be reported with a proper status code anymore.
+	Stream bool
+}
+
+// renderView function renders the view with its Templates or, if they are not set, with the template registry resolved
+// when the handler was created. If the registry couldn't be resolved, registryErr is written with WriteError.
+func renderView(w http.ResponseWriter, writeHeader func(), view View, registry TemplateSet, registryErr error) {
+	templates := view.Templates
+	if templates == nil {
+		if registryErr != nil {
+			WriteError(w, registryErr)
+			return
+		}
+		templates = registry
+	}
+	render(w, writeHeader, view.Stream, func(wr io.Writer) error {
+		return templates.ExecuteTemplate(wr, view.Name, view.Model)
+	})
+}
+
+// render executes the template into the pooled buffer and writes the response only if rendering succeeded, otherwise
+// it responds with WriteError. If stream is set, the template is executed directly to the response: the status code
+// is already sent by the time rendering fails, so the error is logged and the response is aborted with
+// http.ErrAbortHandler, which makes the client see the truncated response as failed rather than complete.
+func render(w http.ResponseWriter, writeHeader func(), stream bool, execute func(io.Writer) error) {
+	if stream {
+		writeHeader()
+		if err := execute(w); err != nil {
+			logrus.WithError(err).Error("Template rendering failed, aborting streamed response")
+			panic(http.ErrAbortHandler)
+		}
+		return
+	}
+	buf := renderBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			renderBufferPool.Put(buf)
+		}
+	}()
+	if err := execute(buf); err != nil {
+		WriteError(w, fmt.Errorf("template rendering failed: %w", err))
+		return
+	}
+	writeHeader()
+	if _, err := buf.WriteTo(w); err != nil {
+		panic(err)
+	}
+}
@@ -16,7 +16,8 @@ package web
 
 import (
 	"context"
-	"encoding"
+	"crypto/x509"
+	"errors"
 	"github.com/goioc/di"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -26,19 +27,34 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	textTemplate "text/template"
 )
 
 const (
-	methods = "web.methods"
-	path    = "web.path"
-	queries = "web.queries"
-	headers = "web.headers"
-	matcher = "web.matcher"
+	methods     = "web.methods"
+	path        = "web.path"
+	queries     = "web.queries"
+	headers     = "web.headers"
+	matcher     = "web.matcher"
+	group       = "web.group"
+	middleware  = "web.middleware"
+	serverTag   = "web.server"
+	qualifiers  = "web.qualifiers"
+	summary     = "web.summary"
+	description = "web.description"
+	tags        = "web.tags"
+	deprecated  = "web.deprecated"
+	example     = "web.example"
+	doc         = "web.doc"
 )
 
-var middlewareFunctionsInternal []mux.MiddlewareFunc
+var (
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	viewType  = reflect.TypeOf((*View)(nil)).Elem()
+)
 
 // Endpoint is an interface representing web endpoint.
 type Endpoint interface {
@@ -46,11 +62,6 @@ type Endpoint interface {
 	HandlerFuncName() string
 }
 
-// Use function registers middleware.
-func Use(middlewareFunctions ...mux.MiddlewareFunc) {
-	middlewareFunctionsInternal = middlewareFunctions
-}
-
 // ListenAndServe function wraps http.ListenAndServe(...), automatically creating endpoints from registered beans.
 func ListenAndServe(addr string) error {
 	router, err := CreateRouter()
@@ -69,12 +80,36 @@ func ListenAndServeTLS(addr, certFile, keyFile string) error {
 	return http.ListenAndServeTLS(addr, certFile, keyFile, router)
 }
 
-// CreateRouter function creates *mux.Router (which implements http.Handler interface).
+// CreateRouter function creates *mux.Router (which implements http.Handler interface) for the endpoints of the default
+// server (i.e. the ones without `web.server` tag).
 func CreateRouter() (*mux.Router, error) {
+	return CreateServerRouter("")
+}
+
+// CreateServerRouter function creates *mux.Router for the endpoints of the server with the given name (i.e. the ones
+// tagged with `web.server` of the same value).
+func CreateServerRouter(serverName string) (*mux.Router, error) {
 	router := mux.NewRouter()
 	router.Use(di.Middleware)
 	router.Use(middlewareFunctionsInternal...)
-	err := registerHandlers(router)
+	middlewareFunctions, err := middlewareBeans()
+	if err != nil {
+		return nil, err
+	}
+	router.Use(middlewareFunctions...)
+	err = loadTemplateRegistry()
+	if err != nil {
+		return nil, err
+	}
+	err = registerHandlers(router, serverName)
+	if err != nil {
+		return nil, err
+	}
+	err = registerOpenAPIHandler(router, serverName)
+	if err != nil {
+		return nil, err
+	}
+	err = registerRoutesHandler(router, serverName)
 	if err != nil {
 		return nil, err
 	}
@@ -85,65 +120,258 @@ func CreateRouter() (*mux.Router, error) {
 	return router, nil
 }
 
-func registerHandlers(router *mux.Router) error {
+// registerHandlers function registers the endpoints and the controllers in the order of their bean IDs, followed by the
+// function endpoints in the order of registration, so that the routes are matched in a deterministic order. Routes of a
+// group are matched together, at the position of the first route of the group.
+func registerHandlers(router *mux.Router, serverName string) error {
 	logrus.Trace("Registering endpoints...")
 	endpointType := reflect.TypeOf((*Endpoint)(nil)).Elem()
-	for beanID, beanType := range di.GetBeanTypes() {
-		if !beanType.Implements(endpointType) || di.GetBeanScopes()[beanID] != di.Singleton {
-			continue
+	controllerType := reflect.TypeOf((*Controller)(nil)).Elem()
+	groupRouters := make(map[string]*mux.Router)
+	beanTypes := di.GetBeanTypes()
+	for _, beanID := range sortedBeanIDs(beanTypes) {
+		beanType := beanTypes[beanID]
+		if beanType.Implements(endpointType) {
+			err := registerHandler(router, groupRouters, serverName, beanID, beanType)
+			if err != nil {
+				return err
+			}
 		}
-		err := registerHandler(router, beanID, beanType.Elem())
-		if err != nil {
-			return err
+		if beanType.Implements(controllerType) {
+			err := registerControllerHandlers(router, groupRouters, serverName, beanID, beanType)
+			if err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	return registerFunctionHandlers(router, groupRouters, serverName)
+}
+
+// sortedBeanIDs function returns the IDs of the beans, sorted.
+func sortedBeanIDs(beanTypes map[string]reflect.Type) []string {
+	beanIDs := make([]string, 0, len(beanTypes))
+	for beanID := range beanTypes {
+		beanIDs = append(beanIDs, beanID)
+	}
+	sort.Strings(beanIDs)
+	return beanIDs
 }
 
-func registerHandler(router *mux.Router, beanID string, beanType reflect.Type) error {
-	endpoint, err := di.GetInstanceSafe(beanID)
+func registerHandler(router *mux.Router, groupRouters map[string]*mux.Router, serverName string, beanID string, beanType reflect.Type) error {
+	routeSpec, err := endpointRouteSpec(beanType.Elem())
 	if err != nil {
 		return err
 	}
-	route := router.Name(beanID)
+	if routeSpec.Server != serverName {
+		return nil
+	}
+	endpoint, resolveEndpoint, err := beanResolver(beanID, beanType)
+	if err != nil {
+		return err
+	}
+	router, err = getGroupRouter(router, groupRouters, routeSpec.Group)
+	if err != nil {
+		return err
+	}
+	route, err := configureRoute(router.Name(beanID), routeSpec)
+	if err != nil {
+		return err
+	}
+	handler, err := createHandler(beanType, endpoint.(Endpoint).HandlerFuncName(), routeSpec.Qualifiers, resolveEndpoint)
+	if err != nil {
+		return err
+	}
+	handler, err = applyMiddleware(handler, routeSpec.Middleware)
+	if err != nil {
+		return err
+	}
+	route.Handler(handler)
+	return nil
+}
+
+func endpointRouteSpec(beanType reflect.Type) (RouteSpec, error) {
+	var routeSpec RouteSpec
 	for i := 0; i < beanType.NumField(); i++ {
 		field := beanType.Field(i)
 		tag := field.Tag
 		if value, ok := tag.Lookup(methods); ok {
-			methods := strings.Split(value, ",")
-			route = route.Methods(methods...)
+			routeSpec.Methods = splitList(value)
 		}
 		if value, ok := tag.Lookup(path); ok {
-			route = route.Path(value)
+			routeSpec.Path = value
 		}
 		if value, ok := tag.Lookup(queries); ok {
-			queries := strings.Split(value, ",")
-			route = route.Queries(queries...)
+			routeSpec.Queries = splitList(value)
 		}
 		if value, ok := tag.Lookup(headers); ok {
-			headers := strings.Split(value, ",")
-			route = route.Headers(headers...)
+			routeSpec.Headers = splitList(value)
 		}
 		if value, ok := tag.Lookup(matcher); ok {
-			instance, err := di.GetInstanceSafe(value)
+			routeSpec.Matcher = value
+		}
+		if value, ok := tag.Lookup(group); ok {
+			routeSpec.Group = value
+		}
+		if value, ok := tag.Lookup(middleware); ok {
+			routeSpec.Middleware = splitList(value)
+		}
+		if value, ok := tag.Lookup(serverTag); ok {
+			routeSpec.Server = value
+		}
+		if value, ok := tag.Lookup(qualifiers); ok {
+			qualifiers, err := parseQualifiers(value)
 			if err != nil {
-				return err
+				return routeSpec, err
+			}
+			routeSpec.Qualifiers = qualifiers
+		}
+		if value, ok := tag.Lookup(summary); ok {
+			routeSpec.Summary = value
+		}
+		if value, ok := tag.Lookup(description); ok {
+			routeSpec.Description = value
+		}
+		if value, ok := tag.Lookup(tags); ok {
+			routeSpec.Tags = splitList(value)
+		}
+		if value, ok := tag.Lookup(deprecated); ok {
+			isDeprecated, err := strconv.ParseBool(value)
+			if err != nil {
+				return routeSpec, errors.New("invalid " + deprecated + " value: " + value)
 			}
-			matcher := instance.(*mux.MatcherFunc)
-			route = route.MatcherFunc(*matcher)
+			routeSpec.Deprecated = isDeprecated
 		}
 	}
-	route.Handler(createHandler(endpoint.(Endpoint)))
-	return nil
+	return routeSpec, nil
+}
+
+// splitList function splits the comma-separated value of a list tag, trimming the spaces around the elements, so that
+// e.g. "a, b" is the same as "a,b".
+func splitList(value string) []string {
+	elements := strings.Split(value, ",")
+	for i, element := range elements {
+		elements[i] = strings.TrimSpace(element)
+	}
+	return elements
+}
+
+// configureRoute function adds the matchers of the route specification to the route. Path is matched before the
+// methods: mux clears the method mismatch of the previous routes once any matcher of the next route succeeds, so
+// otherwise the routes with other paths but the same method would turn 405 Method Not Allowed into 404 Not Found.
+func configureRoute(route *mux.Route, routeSpec RouteSpec) (*mux.Route, error) {
+	if routeSpec.Path != "" {
+		route = route.Path(routeSpec.Path)
+	}
+	if len(routeSpec.Methods) > 0 {
+		route = route.Methods(routeSpec.Methods...)
+	}
+	if len(routeSpec.Queries) > 0 {
+		route = route.Queries(routeSpec.Queries...)
+	}
+	if len(routeSpec.Headers) > 0 {
+		route = route.Headers(routeSpec.Headers...)
+	}
+	if routeSpec.Matcher != "" {
+		instance, err := di.GetInstanceSafe(routeSpec.Matcher)
+		if err != nil {
+			return nil, err
+		}
+		matcher := instance.(*mux.MatcherFunc)
+		route = route.MatcherFunc(*matcher)
+	}
+	return route, route.GetError()
 }
 
-func createHandler(endpoint Endpoint) http.Handler {
-	handlerFunc := reflect.ValueOf(endpoint).MethodByName(endpoint.HandlerFuncName())
+// beanResolver returns the bean instance to read the routing metadata from (singleton instance itself or an empty
+// instance for other scopes) and a function resolving the instance that serves the particular request.
+func beanResolver(beanID string, beanType reflect.Type) (interface{}, func(*http.Request) interface{}, error) {
+	switch di.GetBeanScopes()[beanID] {
+	case di.Singleton:
+		instance, err := di.GetInstanceSafe(beanID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return instance, func(*http.Request) interface{} {
+			return instance
+		}, nil
+	default:
+		return reflect.New(beanType.Elem()).Interface(), func(r *http.Request) interface{} {
+			return beanInstance(r, beanID)
+		}, nil
+	}
+}
+
+func createHandler(beanType reflect.Type, handlerFuncName string, qualifiers map[int]string, resolveBean func(*http.Request) interface{}) (http.Handler, error) {
+	handlerFunc, ok := beanType.MethodByName(handlerFuncName)
+	if !ok {
+		return nil, errors.New("handler method not found: " + handlerFuncName)
+	}
+	if handlerFactory, ok := getHandlerFactory(beanType, handlerFuncName); ok {
+		injectedArguments, err := beanArguments(handlerFunc.Type, 1, qualifiers)
+		if err != nil {
+			return nil, err
+		}
+		if len(injectedArguments) == 0 {
+			return handlerFactory(resolveBean), nil
+		}
+		logrus.WithField("handler", beanType.String()+"."+handlerFuncName).
+			Warn("Generated handler doesn't support bean injection, falling back to reflection")
+	}
+	return newHandler(handlerFunc.Func, qualifiers, nil, resolveBean)
+}
+
+// WriteError function logs the error returned by the handler and responds with 500 Internal Server Error. Used for the
+// errors of the function endpoints and the type-safe JSON endpoints, and for the template rendering failures.
+func WriteError(w http.ResponseWriter, err error) {
+	logrus.WithError(err).Error("Handler failed")
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// newHandler function creates http handler calling the function with the arguments resolved from the request. If
+// resolveReceiver is not nil, the function is a method expression and its first argument is the resolved receiver.
+// Path variables, if given, are bound to the scalar arguments in order (see pathArguments). Trailing error result of
+// the plain functions is written with WriteError, while the methods of the endpoints and controllers treat it as any
+// other result for compatibility.
+func newHandler(handlerFunc reflect.Value, qualifiers map[int]string, pathVariables []string, resolveReceiver func(*http.Request) interface{}) (http.Handler, error) {
 	handlerFuncType := handlerFunc.Type()
+	firstArgument := 0
+	if resolveReceiver != nil {
+		firstArgument = 1
+	}
+	injectedArguments, err := beanArguments(handlerFuncType, firstArgument, qualifiers)
+	if err != nil {
+		return nil, err
+	}
+	boundArguments := pathArguments(handlerFuncType, firstArgument, pathVariables, injectedArguments)
+	// the template registry is resolved once, and only for the handlers that may need it
+	var registry TemplateSet
+	var registryErr error
+	for i := 0; i < handlerFuncType.NumOut(); i++ {
+		if handlerFuncType.Out(i) == viewType {
+			registry, registryErr = templateRegistry()
+		}
+	}
+	returnsError := resolveReceiver == nil && handlerFuncType.NumOut() > 0 && handlerFuncType.Out(handlerFuncType.NumOut()-1) == errorType
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		arguments := make([]reflect.Value, 0)
-		for i := 0; i < handlerFuncType.NumIn(); i++ {
+		arguments := make([]reflect.Value, 0, handlerFuncType.NumIn())
+		if resolveReceiver != nil {
+			arguments = append(arguments, reflect.ValueOf(resolveReceiver(r)))
+		}
+		for i := firstArgument; i < handlerFuncType.NumIn(); i++ {
 			argument := handlerFuncType.In(i)
+			if beanID, ok := injectedArguments[i]; ok {
+				arguments = append(arguments, reflect.ValueOf(beanInstance(r, beanID)))
+				continue
+			}
+			if name, ok := boundArguments[i]; ok {
+				value, err := pathValue(argument, mux.Vars(r)[name])
+				if err != nil {
+					http.Error(w, "invalid path variable "+name+": "+err.Error(), http.StatusBadRequest)
+					return
+				}
+				arguments = append(arguments, value)
+				continue
+			}
 			switch argument {
 			case reflect.TypeOf((*context.Context)(nil)).Elem():
 				arguments = append(arguments, reflect.ValueOf(r.Context()))
@@ -173,44 +401,43 @@ func createHandler(endpoint Endpoint) http.Handler {
 				arguments = append(arguments, reflect.ValueOf(mux.Vars(r)))
 			case reflect.TypeOf((url.Values)(nil)):
 				arguments = append(arguments, reflect.ValueOf(r.URL.Query()))
+			case reflect.TypeOf((*x509.Certificate)(nil)):
+				arguments = append(arguments, reflect.ValueOf(RequestClientCertificate(r)))
+			case reflect.TypeOf((*ClientIdentity)(nil)):
+				arguments = append(arguments, reflect.ValueOf(RequestClientIdentity(r)))
 			default:
 				all, err := ioutil.ReadAll(r.Body)
 				if err != nil {
 					panic(err)
 				}
-				body := reflect.New(argument).Interface()
-				typeOfBody := reflect.TypeOf(body)
-				if typeOfBody.Implements(reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()) {
-					binaryUnmarshaler := body.(encoding.BinaryUnmarshaler)
-					if err := binaryUnmarshaler.UnmarshalBinary(all); err != nil {
-						panic(err)
-					}
-					arguments = append(arguments, reflect.ValueOf(binaryUnmarshaler).Elem())
-				} else if typeOfBody.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
-					textUnmarshaler := body.(encoding.TextUnmarshaler)
-					if err := textUnmarshaler.UnmarshalText(all); err != nil {
-						panic(err)
-					}
-					arguments = append(arguments, reflect.ValueOf(textUnmarshaler).Elem())
-				} else {
-					webResponseSerializer, err := di.GetInstanceSafe(GoiocSerializer)
-					if err != nil {
-						panic(err)
-					}
-					if err := webResponseSerializer.(Serializer).Deserialize(all, &body); err != nil {
-						panic(err)
-					}
-					arguments = append(arguments, reflect.ValueOf(body).Elem())
+				body := reflect.New(argument)
+				if err := decodeObject(all, body.Interface()); err != nil {
+					panic(err)
 				}
+				arguments = append(arguments, body.Elem())
 			}
 		}
 		results := handlerFunc.Call(arguments)
+		if returnsError {
+			if err, _ := results[len(results)-1].Interface().(error); err != nil {
+				WriteError(w, err)
+				return
+			}
+			results = results[:len(results)-1]
+		}
+		statusCode := 0
+		writeHeader := func() {
+			if statusCode != 0 {
+				w.WriteHeader(statusCode)
+				statusCode = 0
+			}
+		}
 	L:
 		for i, result := range results {
 			value := result.Interface()
 			switch result.Type() {
 			case reflect.TypeOf((*int)(nil)).Elem():
-				w.WriteHeader(value.(int))
+				statusCode = value.(int)
 			case reflect.TypeOf((*http.Header)(nil)).Elem():
 				for k, v := range value.(http.Header) {
 					for _, header := range v {
@@ -218,22 +445,26 @@ func createHandler(endpoint Endpoint) http.Handler {
 					}
 				}
 			case reflect.TypeOf((*string)(nil)).Elem():
+				writeHeader()
 				if _, err := w.Write([]byte(value.(string))); err != nil {
 					panic(err)
 				}
 				break L
 			case reflect.TypeOf((*[]byte)(nil)).Elem():
+				writeHeader()
 				if _, err := w.Write(value.([]byte)); err != nil {
 					panic(err)
 				}
 				break L
 			case reflect.TypeOf((*io.Reader)(nil)).Elem():
+				writeHeader()
 				readCloser := value.(io.Reader)
 				if _, err := io.Copy(w, readCloser); err != nil {
 					panic(err)
 				}
 				break L
 			case reflect.TypeOf((*io.ReadCloser)(nil)).Elem():
+				writeHeader()
 				readCloser := value.(io.ReadCloser)
 				if _, err := io.Copy(w, readCloser); err != nil {
 					panic(err)
@@ -244,71 +475,55 @@ func createHandler(endpoint Endpoint) http.Handler {
 				break L
 			case reflect.TypeOf((*htmlTemplate.Template)(nil)).Elem():
 				tmpl := value.(htmlTemplate.Template)
-				if err := tmpl.Execute(w, results[i+1].Interface()); err != nil {
-					panic(err)
-				}
+				model := results[i+1].Interface()
+				render(w, writeHeader, false, func(wr io.Writer) error {
+					return tmpl.Execute(wr, model)
+				})
 				break L
 			case reflect.TypeOf((*textTemplate.Template)(nil)).Elem():
 				tmpl := value.(textTemplate.Template)
-				if err := tmpl.Execute(w, results[i+1].Interface()); err != nil {
-					panic(err)
-				}
+				model := results[i+1].Interface()
+				render(w, writeHeader, false, func(wr io.Writer) error {
+					return tmpl.Execute(wr, model)
+				})
+				break L
+			case reflect.TypeOf((*htmlTemplate.Template)(nil)):
+				tmpl := value.(*htmlTemplate.Template)
+				model := results[i+1].Interface()
+				render(w, writeHeader, false, func(wr io.Writer) error {
+					return tmpl.Execute(wr, model)
+				})
+				break L
+			case reflect.TypeOf((*textTemplate.Template)(nil)):
+				tmpl := value.(*textTemplate.Template)
+				model := results[i+1].Interface()
+				render(w, writeHeader, false, func(wr io.Writer) error {
+					return tmpl.Execute(wr, model)
+				})
+				break L
+			case viewType:
+				renderView(w, writeHeader, value.(View), registry, registryErr)
 				break L
 			default:
-				if result.Type().Implements(reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()) ||
-					reflect.PtrTo(result.Type()).Implements(reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()) {
-					marshaler := value.(encoding.BinaryMarshaler)
-					body, err := marshaler.MarshalBinary()
-					if err != nil {
-						panic(err)
-					}
-					if _, err = w.Write(body); err != nil {
-						panic(err)
-					}
-				} else if result.Type().Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) ||
-					reflect.PtrTo(result.Type()).Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) {
-					marshaler := value.(encoding.TextMarshaler)
-					body, err := marshaler.MarshalText()
-					if err != nil {
-						panic(err)
-					}
-					if _, err = w.Write(body); err != nil {
-						panic(err)
-					}
-				} else {
-					webResponseSerializer, err := di.GetInstanceSafe(GoiocSerializer)
-					if err != nil {
-						panic(err)
-					}
-					body, err := webResponseSerializer.(Serializer).Serialize(value)
-					if err != nil {
-						panic(err)
-					}
-					if _, err = w.Write(body); err != nil {
-						panic(err)
-					}
-				}
+				WriteObject(w, statusCode, value)
+				statusCode = 0
 				break L
 			}
 		}
-	})
+		writeHeader()
+	}), nil
 }
 
 func walk(router *mux.Router) error {
 	logrus.Trace("Registered endpoints: ")
 	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
-		pathTemplate, err := route.GetPathTemplate()
-		if err != nil {
-			return err
-		}
-		methods, err := route.GetMethods()
-		if err != nil {
-			return err
-		}
-		queriesTemplates, err := route.GetQueriesTemplates()
-		if err != nil {
+		if err := route.GetError(); err != nil {
 			return err
 		}
+		// the route is valid, so the errors below only mean that the corresponding matcher is absent
+		pathTemplate, _ := route.GetPathTemplate()
+		methods, _ := route.GetMethods()
+		queriesTemplates, _ := route.GetQueriesTemplates()
 		logrus.WithFields(logrus.Fields{
 			"route":           pathTemplate,
 			"methods":         methods,
@@ -17,6 +17,7 @@ package web
 import (
 	"bytes"
 	"context"
+	"errors"
 	"github.com/goioc/di"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +29,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	textTemplate "text/template"
@@ -386,6 +388,122 @@ func (e *endpoint19) REST() (textTemplate.Template, interface{}) {
 	}
 }
 
+type endpoint20 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint20"`
+}
+
+func (e endpoint20) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint20) REST() (*htmlTemplate.Template, interface{}) {
+	tmpl := htmlTemplate.Must(htmlTemplate.New("test").Parse(htmlTmpl))
+	return tmpl, todoPageData{
+		PageTitle: "My TODO list",
+		Todos: []todo{
+			{Title: "Task 1", Done: false},
+			{Title: "Task 2", Done: true},
+			{Title: "Task 3", Done: true},
+		},
+	}
+}
+
+type endpoint21 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint21"`
+}
+
+func (e endpoint21) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint21) REST() (*textTemplate.Template, interface{}) {
+	tmpl := textTemplate.Must(textTemplate.New("test").Parse(htmlTmpl))
+	return tmpl, todoPageData{
+		PageTitle: "My TODO list",
+		Todos: []todo{
+			{Title: "Task 1", Done: false},
+			{Title: "Task 2", Done: true},
+			{Title: "Task 3", Done: true},
+		},
+	}
+}
+
+var templateSet = htmlTemplate.Must(htmlTemplate.New("header").Parse(`<h1>{{.}}</h1>`))
+
+func init() {
+	htmlTemplate.Must(templateSet.New("page").Parse(`{{template "header" .PageTitle}}<p>{{len .Todos}}</p>`))
+}
+
+type endpoint22 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint22"`
+}
+
+func (e endpoint22) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint22) REST() View {
+	return View{
+		Templates: templateSet,
+		Name:      "page",
+		Model: todoPageData{
+			PageTitle: "My TODO list",
+			Todos:     []todo{{Title: "Task 1", Done: false}},
+		},
+	}
+}
+
+type endpoint30 struct {
+	scope    interface{} `di.scope:"request"`
+	method   interface{} `web.methods:"GET"`
+	path     interface{} `web.path:"/endpoint30"`
+	requests int
+}
+
+func (e endpoint30) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint30) REST() string {
+	e.requests++
+	return strconv.Itoa(e.requests)
+}
+
+type endpoint31 struct {
+	scope    interface{} `di.scope:"prototype"`
+	method   interface{} `web.methods:"GET"`
+	path     interface{} `web.path:"/endpoint31"`
+	requests int
+}
+
+func (e endpoint31) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint31) REST() string {
+	e.requests++
+	return strconv.Itoa(e.requests)
+}
+
+type endpoint35 struct {
+	method interface{} `web.methods:"GET"`
+	path   interface{} `web.path:"/endpoint35"`
+}
+
+func (e endpoint35) HandlerFuncName() string {
+	return "REST"
+}
+
+func (e *endpoint35) REST(queryParams url.Values) (string, error) {
+	if queryParams.Get("fail") != "" {
+		return "partial", errors.New("failed")
+	}
+	return "ok", nil
+}
+
 type TestSuite struct {
 	suite.Suite
 }
@@ -436,6 +554,67 @@ func (suite *TestSuite) SetupSuite() {
 	assert.NoError(suite.T(), err)
 	_, err = di.RegisterBean("endpoint19", reflect.TypeOf((*endpoint19)(nil)))
 	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint20", reflect.TypeOf((*endpoint20)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint21", reflect.TypeOf((*endpoint21)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint22", reflect.TypeOf((*endpoint22)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint23", reflect.TypeOf((*endpoint23)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint24", reflect.TypeOf((*endpoint24)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint25", reflect.TypeOf((*endpoint25)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("controller1", reflect.TypeOf((*controller1)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance("group1", group1)
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint26", reflect.TypeOf((*endpoint26)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance("middleware1", orderMiddleware("1"))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance("middleware2", orderMiddleware("2"))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint27", reflect.TypeOf((*endpoint27)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance("orderedMiddleware2", &orderedMiddleware{order: 2})
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance("orderedMiddleware1", &orderedMiddleware{order: 1})
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance("lifecycleBean", testLifecycleBean)
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint28", reflect.TypeOf((*endpoint28)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint29", reflect.TypeOf((*endpoint29)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint30", reflect.TypeOf((*endpoint30)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint31", reflect.TypeOf((*endpoint31)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint35", reflect.TypeOf((*endpoint35)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance("testGreeter", &testGreeter{greeting: "hello"})
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("transaction1", reflect.TypeOf((*testTransaction)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("transaction2", reflect.TypeOf((*testTransaction)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint32", reflect.TypeOf((*endpoint32)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("endpoint33", reflect.TypeOf((*endpoint33)(nil)))
+	assert.NoError(suite.T(), err)
+	RegisterHandlerFactory(reflect.TypeOf((*endpoint33)(nil)), "REST", endpoint33HandlerFactory)
+	_, err = di.RegisterBean("endpoint34", reflect.TypeOf((*endpoint34)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance(GoiocOpenAPI, openAPIConfig)
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance(GoiocRoutes, routesConfig)
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBean("shoutFunctions", reflect.TypeOf((*shoutFunctions)(nil)))
+	assert.NoError(suite.T(), err)
+	_, err = di.RegisterBeanInstance(GoiocTemplateRegistry, newTestTemplateRegistry())
+	assert.NoError(suite.T(), err)
 	err = di.InitializeContainer()
 	assert.NoError(suite.T(), err)
 	Use(func(next http.Handler) http.Handler {
@@ -443,6 +622,18 @@ func (suite *TestSuite) SetupSuite() {
 			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), di.BeanKey("key"), "value")))
 		})
 	})
+	Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("use", "second")
+			next.ServeHTTP(w, r)
+		})
+	})
+	HandleRoute(RouteSpec{Methods: []string{"GET"}, Path: "/function1/{id}", Qualifiers: map[int]string{1: InjectByType}}, function1)
+	Handle("/function2", function2)
+	Handle("GET /function3/{id}", function3)
+	Handle("POST /function4/{name}/{version:[0-9]+}", function4)
+	JSON("POST", "/json1", json1)
+	JSON("PUT", "/json2/{id}", json2)
 	router, err := CreateRouter()
 	assert.NoError(suite.T(), err)
 	server = httptest.NewServer(router)
@@ -650,3 +841,82 @@ func (suite *TestSuite) TestEndpoint19() {
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), htmlPage, string(all))
 }
+
+func (suite *TestSuite) TestEndpoint20() {
+	response, err := http.Get(server.URL + "/endpoint20")
+	assert.NotNil(suite.T(), response)
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), htmlPage, string(all))
+}
+
+func (suite *TestSuite) TestEndpoint21() {
+	response, err := http.Get(server.URL + "/endpoint21")
+	assert.NotNil(suite.T(), response)
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), htmlPage, string(all))
+}
+
+func (suite *TestSuite) TestEndpoint22() {
+	response, err := http.Get(server.URL + "/endpoint22")
+	assert.NotNil(suite.T(), response)
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "<h1>My TODO list</h1><p>1</p>", string(all))
+}
+
+func (suite *TestSuite) TestEndpoint30() {
+	for i := 0; i < 2; i++ {
+		response, err := http.Get(server.URL + "/endpoint30")
+		assert.NotNil(suite.T(), response)
+		assert.NoError(suite.T(), err)
+		all, err := ioutil.ReadAll(response.Body)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), "1", string(all))
+	}
+}
+
+func (suite *TestSuite) TestEndpoint35() {
+	response, err := http.Get(server.URL + "/endpoint35")
+	assert.NoError(suite.T(), err)
+	all, err := ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "ok", string(all))
+	response, err = http.Get(server.URL + "/endpoint35?fail=true")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, response.StatusCode)
+	all, err = ioutil.ReadAll(response.Body)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "partial", string(all))
+}
+
+func (suite *TestSuite) TestEndpoint31() {
+	for i := 0; i < 2; i++ {
+		response, err := http.Get(server.URL + "/endpoint31")
+		assert.NotNil(suite.T(), response)
+		assert.NoError(suite.T(), err)
+		all, err := ioutil.ReadAll(response.Body)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), "1", string(all))
+	}
+}
+
+func (suite *TestSuite) TestEndpointRouteSpecLists() {
+	routeSpec, err := endpointRouteSpec(reflect.TypeOf(struct {
+		method     interface{} `web.methods:"GET, POST"`
+		queries    interface{} `web.queries:"page, {page}"`
+		headers    interface{} `web.headers:"X-Mode, test"`
+		middleware interface{} `web.middleware:"middleware1, middleware2"`
+		tags       interface{} `web.tags:"users, admin"`
+	}{}))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"GET", "POST"}, routeSpec.Methods)
+	assert.Equal(suite.T(), []string{"page", "{page}"}, routeSpec.Queries)
+	assert.Equal(suite.T(), []string{"X-Mode", "test"}, routeSpec.Headers)
+	assert.Equal(suite.T(), []string{"middleware1", "middleware2"}, routeSpec.Middleware)
+	assert.Equal(suite.T(), []string{"users", "admin"}, routeSpec.Tags)
+}